@@ -0,0 +1,113 @@
+package funcdep
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// ArmstrongRelation generates a small sample table for the Relation which
+// satisfies exactly the functional dependencies implied by its FuncDeps, and
+// violates every other functional dependency. The header lists the
+// attributes of the Relation, in order.
+//
+// The table has a base row of zeros, plus one row for every closed attribute
+// set which is not the intersection of larger closed sets. Each such row
+// agrees with the base row on the closed set, and holds its own row number
+// everywhere else. Enumerating the closed sets may take time exponential in
+// the number of attributes.
+func (r *Relation) ArmstrongRelation() (header []string, rows [][]string) {
+	c := r.newCloser()
+	n := len(r.Attrs)
+	cols := make([]int, n)
+	for j, a := range r.Attrs {
+		cols[j], _ = c.ix.Pos(a)
+		header = append(header, string(a))
+	}
+
+	// closed sets are generated in lectic order using Ganter's NextClosure,
+	// treating the columns as positions 0..n-1.
+	prefix := func(x Bitset, i int) Bitset {
+		res := NewBitset(c.ix.Len())
+		for _, p := range cols[:i] {
+			if x.Has(p) {
+				res.Set(p)
+			}
+		}
+		return res
+	}
+	var closed []Bitset
+	cur := c.closure(NewBitset(c.ix.Len()))
+	closed = append(closed, cur)
+	for {
+		next := Bitset(nil)
+		for i := n - 1; i >= 0; i-- {
+			if cur.Has(cols[i]) {
+				continue
+			}
+			x := prefix(cur, i)
+			x.Set(cols[i])
+			b := c.closure(x)
+			if prefix(b, i).Equal(prefix(cur, i)) {
+				next = b
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		cur = next
+		closed = append(closed, cur)
+	}
+
+	// only the meet-irreducible closed sets are needed, since agreeing
+	// rows already produce every intersection of them.
+	all := c.ix.Bits(r.Attrs)
+	var generators []Bitset
+	for _, x := range closed {
+		if x.Contains(all) {
+			continue
+		}
+		meet := all.Clone()
+		for _, y := range closed {
+			if y.Contains(x) && !x.Contains(y) {
+				meet = meet.Intersection(y)
+			}
+		}
+		if !meet.Equal(x) {
+			generators = append(generators, x)
+		}
+	}
+
+	base := make([]string, n)
+	for j := range base {
+		base[j] = "0"
+	}
+	rows = append(rows, base)
+	for i, x := range generators {
+		row := make([]string, n)
+		for j, p := range cols {
+			if x.Has(p) {
+				row[j] = "0"
+			} else {
+				row[j] = strconv.Itoa(i + 1)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+// WriteArmstrongCSV writes the ArmstrongRelation of r as CSV data with a
+// single-line header.
+func (r *Relation) WriteArmstrongCSV(w io.Writer) error {
+	header, rows := r.ArmstrongRelation()
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
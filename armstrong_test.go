@@ -0,0 +1,32 @@
+package funcdep_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/joiningdata/funcdep"
+	"github.com/joiningdata/funcdep/discover"
+)
+
+// TestArmstrongRediscover checks that discovering the functional dependencies
+// of an Armstrong relation gives back an equivalent set to the declared ones.
+func TestArmstrongRediscover(t *testing.T) {
+	rng := rand.New(rand.NewSource(19))
+	for i := 0; i < 300; i++ {
+		rel := funcdep.RandomRelation(rng, rng.Intn(6)+2, rng.Intn(7), 3)
+		header, rows := rel.ArmstrongRelation()
+
+		for _, algo := range []string{"levelwise", "agreeset"} {
+			ds := discover.NewDataSet(rel.Name, header, rows)
+			res, err := ds.Discover(discover.Options{Algorithm: algo})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, declared, found := funcdep.Equivalent(rel.FuncDeps, res.Relation.FuncDeps)
+			if !ok {
+				t.Fatalf("%s on the Armstrong relation of\n%s\nmissed %v and found %v",
+					algo, rel, declared, found)
+			}
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package funcdep
+
+import "math/bits"
+
+// Bitset is a set of attribute positions within an AttrIndex, stored as a
+// bit vector. Set operations run in time proportional to the number of
+// words, instead of the number of attributes.
+//
+// Bitsets of differing lengths may be combined; missing words are zero.
+type Bitset []uint64
+
+// NewBitset returns an empty Bitset with room for n attribute positions.
+func NewBitset(n int) Bitset {
+	return make(Bitset, (n+63)/64)
+}
+
+// Has returns true if position i is in the set.
+func (b Bitset) Has(i int) bool {
+	w := i / 64
+	return w < len(b) && b[w]&(1<<uint(i%64)) != 0
+}
+
+// Set adds position i to the set, growing it if necessary.
+func (b *Bitset) Set(i int) {
+	w := i / 64
+	for len(*b) <= w {
+		*b = append(*b, 0)
+	}
+	(*b)[w] |= 1 << uint(i%64)
+}
+
+// Clear removes position i from the set.
+func (b Bitset) Clear(i int) {
+	w := i / 64
+	if w < len(b) {
+		b[w] &^= 1 << uint(i%64)
+	}
+}
+
+// Len returns the number of positions in the set.
+func (b Bitset) Len() int {
+	n := 0
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Empty returns true if the set has no positions.
+func (b Bitset) Empty() bool {
+	for _, w := range b {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a copy of the set.
+func (b Bitset) Clone() Bitset {
+	res := make(Bitset, len(b))
+	copy(res, b)
+	return res
+}
+
+// Equal returns true if both sets contain the same positions.
+func (b Bitset) Equal(other Bitset) bool {
+	n := len(b)
+	if len(other) > n {
+		n = len(other)
+	}
+	for i := 0; i < n; i++ {
+		if b.word(i) != other.word(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains returns true if this set contains all positions of other.
+// (e.g. other is a subset of this)
+func (b Bitset) Contains(other Bitset) bool {
+	for i, w := range other {
+		if w&^b.word(i) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersects returns true if the sets have at least one position in common.
+func (b Bitset) Intersects(other Bitset) bool {
+	for i, w := range b {
+		if w&other.word(i) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// UnionWith adds all positions of other to this set in place.
+func (b *Bitset) UnionWith(other Bitset) {
+	for len(*b) < len(other) {
+		*b = append(*b, 0)
+	}
+	for i, w := range other {
+		(*b)[i] |= w
+	}
+}
+
+// Union of this and the other set, returned as a new Bitset.
+func (b Bitset) Union(other Bitset) Bitset {
+	res := b.Clone()
+	res.UnionWith(other)
+	return res
+}
+
+// Intersection of this and the other set, returned as a new Bitset.
+func (b Bitset) Intersection(other Bitset) Bitset {
+	res := b.Clone()
+	for i := range res {
+		res[i] &= other.word(i)
+	}
+	return res
+}
+
+// Difference removes all positions of other from this set and returns the
+// remaining positions as a new Bitset.
+func (b Bitset) Difference(other Bitset) Bitset {
+	res := b.Clone()
+	for i := range res {
+		res[i] &^= other.word(i)
+	}
+	return res
+}
+
+// Each calls fn for every position in the set, in increasing order.
+func (b Bitset) Each(fn func(i int)) {
+	for wi, w := range b {
+		for w != 0 {
+			t := bits.TrailingZeros64(w)
+			fn(wi*64 + t)
+			w &= w - 1
+		}
+	}
+}
+
+func (b Bitset) word(i int) uint64 {
+	if i < len(b) {
+		return b[i]
+	}
+	return 0
+}
+
+// AttrIndex interns attribute names into dense positions, so that attribute
+// sets can be represented as Bitsets.
+type AttrIndex struct {
+	attrs []Attr
+	pos   map[Attr]int
+}
+
+// NewAttrIndex creates an index over all the attributes in the given sets,
+// numbered in order of first appearance.
+func NewAttrIndex(sets ...AttrSet) *AttrIndex {
+	ix := &AttrIndex{pos: make(map[Attr]int)}
+	for _, s := range sets {
+		for _, a := range s {
+			ix.add(a)
+		}
+	}
+	return ix
+}
+
+// Len returns the number of attributes in the index.
+func (ix *AttrIndex) Len() int {
+	return len(ix.attrs)
+}
+
+// Attr returns the attribute at position i.
+func (ix *AttrIndex) Attr(i int) Attr {
+	return ix.attrs[i]
+}
+
+// Pos returns the position of attribute a, or false if it is not indexed.
+func (ix *AttrIndex) Pos(a Attr) (int, bool) {
+	i, ok := ix.pos[a]
+	return i, ok
+}
+
+// Bits converts an AttrSet into a Bitset. Attributes that are not yet
+// indexed are added to the index.
+func (ix *AttrIndex) Bits(s AttrSet) Bitset {
+	b := NewBitset(ix.Len())
+	for _, a := range s {
+		b.Set(ix.add(a))
+	}
+	return b
+}
+
+// AttrSet converts a Bitset back into an AttrSet, in index order.
+func (ix *AttrIndex) AttrSet(b Bitset) AttrSet {
+	res := make(AttrSet, 0, b.Len())
+	b.Each(func(i int) {
+		res = append(res, ix.attrs[i])
+	})
+	return res
+}
+
+func (ix *AttrIndex) add(a Attr) int {
+	if i, ok := ix.pos[a]; ok {
+		return i
+	}
+	i := len(ix.attrs)
+	ix.attrs = append(ix.attrs, a)
+	ix.pos[a] = i
+	return i
+}
+
+// Index builds an AttrIndex over the attributes of the Relation (and any
+// attributes referenced by its dependencies).
+func (r *Relation) Index() *AttrIndex {
+	ix := NewAttrIndex(r.Attrs)
+	for _, fd := range r.FuncDeps {
+		for _, a := range fd.Left {
+			ix.add(a)
+		}
+		for _, a := range fd.Right {
+			ix.add(a)
+		}
+	}
+	for _, m := range r.MVDs {
+		for _, a := range m.Left {
+			ix.add(a)
+		}
+		for _, a := range m.Right {
+			ix.add(a)
+		}
+	}
+	return ix
+}
+
+// bitFD is a functional dependency over the positions of an AttrIndex.
+type bitFD struct {
+	left, right Bitset
+}
+
+// closer computes attribute closures over a fixed set of functional
+// dependencies, all converted to Bitsets over a shared AttrIndex.
+type closer struct {
+	ix  *AttrIndex
+	fds []bitFD
+	all Bitset
+
+	// uses lists the dependencies with each attribute position on their left side.
+	uses [][]int
+	// counts holds the number of left side attributes of each dependency.
+	counts []int
+	// always lists the dependencies with an empty left side.
+	always []int
+}
+
+// newCloser prepares the functional dependencies of the Relation for
+// repeated closure computations.
+func (r *Relation) newCloser() *closer {
+	c := &closer{ix: r.Index()}
+	c.fds = make([]bitFD, len(r.FuncDeps))
+	for i, fd := range r.FuncDeps {
+		c.fds[i] = bitFD{left: c.ix.Bits(fd.Left), right: c.ix.Bits(fd.Right)}
+	}
+	c.all = c.ix.Bits(r.Attrs)
+	return c
+}
+
+// prepare builds the attribute to dependency lists used by closure.
+func (c *closer) prepare() {
+	c.uses = make([][]int, c.ix.Len())
+	c.counts = make([]int, len(c.fds))
+	c.always = nil
+	for i, fd := range c.fds {
+		fd.left.Each(func(a int) {
+			c.uses[a] = append(c.uses[a], i)
+			c.counts[i]++
+		})
+		if c.counts[i] == 0 {
+			c.always = append(c.always, i)
+		}
+	}
+}
+
+// closure returns the closure of x over the functional dependencies.
+//
+// This is the linear-time LinClosure algorithm of Beeri and Bernstein: each
+// dependency keeps a count of the left side attributes not yet in the
+// closure, and fires once when its count reaches zero. Every attribute is
+// processed at most once, so the cost is linear in the total size of the
+// dependencies, instead of rescanning them all until a fixpoint.
+func (c *closer) closure(x Bitset) Bitset {
+	if c.uses == nil || len(c.uses) != c.ix.Len() {
+		c.prepare()
+	}
+	clo := x.Clone()
+	counts := make([]int, len(c.counts))
+	copy(counts, c.counts)
+
+	queue := make([]int, 0, c.ix.Len())
+	clo.Each(func(a int) {
+		queue = append(queue, a)
+	})
+	size := len(queue)
+	fire := func(fd bitFD) {
+		fd.right.Each(func(b int) {
+			if !clo.Has(b) {
+				clo.Set(b)
+				queue = append(queue, b)
+				size++
+			}
+		})
+	}
+	for _, i := range c.always {
+		fire(c.fds[i])
+	}
+	for len(queue) > 0 {
+		// nothing more can be added once every indexed attribute is in the closure
+		if size == len(c.uses) {
+			break
+		}
+		a := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if a >= len(c.uses) {
+			continue
+		}
+		for _, i := range c.uses[a] {
+			counts[i]--
+			if counts[i] == 0 {
+				fire(c.fds[i])
+			}
+		}
+	}
+	return clo
+}
+
+// closeFD returns a new FuncDep with the closure of fd on the right side.
+func (c *closer) closeFD(fd *FuncDep) *FuncDep {
+	clofd := &FuncDep{}
+	clofd.Left.AddAll(fd.Left)
+	x := c.ix.Bits(fd.Left)
+	x.UnionWith(c.ix.Bits(fd.Right))
+	clofd.Right = c.ix.AttrSet(c.closure(x))
+	return clofd
+}
+
+// isSuperkey returns true if the closure of x contains every attribute.
+func (c *closer) isSuperkey(x Bitset) bool {
+	return c.closure(x).Contains(c.all)
+}
+
+// isKey returns true if x is a superkey and no proper subset of it is.
+func (c *closer) isKey(x Bitset) bool {
+	if !c.isSuperkey(x) {
+		return false
+	}
+	minimal := true
+	y := x.Clone()
+	x.Each(func(i int) {
+		y.Clear(i)
+		if minimal && c.isSuperkey(y) {
+			minimal = false
+		}
+		y.Set(i)
+	})
+	return minimal
+}
+
+// implies returns true if the dependencies imply fd.
+func (c *closer) implies(fd *FuncDep) bool {
+	return c.closure(c.ix.Bits(fd.Left)).Contains(c.ix.Bits(fd.Right))
+}
@@ -0,0 +1,99 @@
+package funcdep
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// fixpointClosure is the closure loop replaced by LinClosure: rescan every
+// dependency until nothing changes.
+func fixpointClosure(c *closer, x Bitset) Bitset {
+	clo := x.Clone()
+	for {
+		changed := false
+		for _, fd := range c.fds {
+			if clo.Contains(fd.left) && !clo.Contains(fd.right) {
+				clo.UnionWith(fd.right)
+				changed = true
+			}
+		}
+		if !changed {
+			return clo
+		}
+	}
+}
+
+// randomSets picks n random attribute sets of 1 to 3 attributes.
+func randomSets(rng *rand.Rand, c *closer, attrs AttrSet, n int) []Bitset {
+	res := make([]Bitset, n)
+	for i := range res {
+		var x AttrSet
+		for k := rng.Intn(3) + 1; k > 0; k-- {
+			x.Add(attrs[rng.Intn(len(attrs))])
+		}
+		res[i] = c.ix.Bits(x)
+	}
+	return res
+}
+
+func TestClosureMatchesFixpoint(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for it := 0; it < 200; it++ {
+		r := randomRelation(rng, 2+rng.Intn(10), rng.Intn(20), 3)
+		c := r.newCloser()
+		for _, x := range randomSets(rng, c, r.Attrs, 20) {
+			got, want := c.closure(x), fixpointClosure(c, x)
+			if !got.Equal(want) {
+				t.Fatalf("closure of %s = %s, want %s\n%s", c.ix.AttrSet(x), c.ix.AttrSet(got), c.ix.AttrSet(want), r)
+			}
+		}
+	}
+}
+
+// BenchmarkClosure compares LinClosure with the fixpoint loop on a wide
+// relation with many small dependencies, like the output of data2fd, and
+// on a chain of dependencies listed in reverse, where the fixpoint loop
+// needs a pass over every dependency for each attribute added.
+func BenchmarkClosure(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	wide := randomRelation(rng, 60, 400, 4)
+	cw := wide.newCloser()
+	cw.prepare()
+	xs := randomSets(rng, cw, wide.Attrs, 100)
+
+	chain := &Relation{Name: "R"}
+	for i := 1; i <= 200; i++ {
+		chain.Attrs.Add(Attr(fmt.Sprintf("A%d", i)))
+	}
+	for i := len(chain.Attrs) - 1; i > 0; i-- {
+		chain.FuncDeps = append(chain.FuncDeps, &FuncDep{
+			Left:  AttrSet{chain.Attrs[i-1]},
+			Right: AttrSet{chain.Attrs[i]},
+		})
+	}
+	cc := chain.newCloser()
+	cc.prepare()
+	first := cc.ix.Bits(AttrSet{chain.Attrs[0]})
+
+	b.Run("Wide/LinClosure", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			cw.closure(xs[i%len(xs)])
+		}
+	})
+	b.Run("Wide/Fixpoint", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			fixpointClosure(cw, xs[i%len(xs)])
+		}
+	})
+	b.Run("Chain/LinClosure", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			cc.closure(first)
+		}
+	})
+	b.Run("Chain/Fixpoint", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			fixpointClosure(cc, first)
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package funcdep
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tableau is the table of symbols used by the chase to test a decomposition.
+// Each column is an attribute of the original relation, and each row starts
+// out as one relation of the decomposition: it holds the distinguished symbol
+// a<j> for every attribute in that relation, and a unique symbol b<i>_<j>
+// everywhere else.
+type Tableau struct {
+	Attrs AttrSet
+	Rows  [][]string
+}
+
+func (t *Tableau) String() string {
+	widths := make([]int, len(t.Attrs))
+	for j, a := range t.Attrs {
+		widths[j] = len(a)
+		for _, row := range t.Rows {
+			if len(row[j]) > widths[j] {
+				widths[j] = len(row[j])
+			}
+		}
+	}
+	line := func(vals []string) string {
+		sb := strings.Builder{}
+		for j, v := range vals {
+			fmt.Fprintf(&sb, "%-*s ", widths[j], v)
+		}
+		return strings.TrimRight(sb.String(), " ")
+	}
+	head := make([]string, len(t.Attrs))
+	for j, a := range t.Attrs {
+		head[j] = string(a)
+	}
+	lines := []string{line(head)}
+	for _, row := range t.Rows {
+		lines = append(lines, line(row))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LosslessJoin uses the chase to decide if joining the relations over the
+// given attribute sets always reproduces the original relation. The tableau
+// is repeatedly updated by equating symbols in rows that agree on the left
+// side of a functional dependency, and by adding rows required by
+// multivalued dependencies. The join is lossless if a row ends up with only
+// distinguished symbols.
+//
+// The final tableau is returned to explain the result. An error is returned
+// if any of the attribute sets has an attribute which is not in r.
+func LosslessJoin(r *Relation, parts []AttrSet) (bool, *Tableau, error) {
+	if err := r.checkParts(parts); err != nil {
+		return false, nil, err
+	}
+	ncols := len(r.Attrs)
+	every := make([]int, ncols)
+	for j := range every {
+		every[j] = j
+	}
+
+	// convert attribute sets to lists of columns
+	colsOf := func(s AttrSet) []int {
+		var res []int
+		for j, a := range r.Attrs {
+			if s.Contains(AttrSet{a}) {
+				res = append(res, j)
+			}
+		}
+		return res
+	}
+
+	// symbol 0 is distinguished in every column, others are unique
+	var rows [][]int
+	for i, p := range parts {
+		row := make([]int, ncols)
+		for j := range row {
+			row[j] = i*ncols + j + 1
+		}
+		for _, j := range colsOf(p) {
+			row[j] = 0
+		}
+		rows = append(rows, row)
+	}
+
+	type colDep struct {
+		left, right []int
+	}
+	var fds, mvds []colDep
+	for _, fd := range r.FuncDeps {
+		fds = append(fds, colDep{colsOf(fd.Left), colsOf(fd.Right)})
+	}
+	for _, m := range r.MVDs {
+		mvds = append(mvds, colDep{colsOf(m.Left), colsOf(m.Right)})
+	}
+
+	agree := func(a, b []int, on []int) bool {
+		for _, j := range on {
+			if a[j] != b[j] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, fd := range fds {
+			for i1, t1 := range rows {
+				for _, t2 := range rows[i1+1:] {
+					if !agree(t1, t2, fd.left) {
+						continue
+					}
+					for _, j := range fd.right {
+						if t1[j] == t2[j] {
+							continue
+						}
+						// keep the smaller symbol, so distinguished ones win
+						from, to := t1[j], t2[j]
+						if from < to {
+							from, to = to, from
+						}
+						for _, t := range rows {
+							if t[j] == from {
+								t[j] = to
+							}
+						}
+						changed = true
+					}
+				}
+			}
+		}
+
+		for _, m := range mvds {
+			for _, t1 := range rows {
+				for _, t2 := range rows {
+					if !agree(t1, t2, m.left) {
+						continue
+					}
+					// t1 on X and Y, t2 everywhere else
+					nt := make([]int, ncols)
+					copy(nt, t2)
+					for _, j := range m.left {
+						nt[j] = t1[j]
+					}
+					for _, j := range m.right {
+						nt[j] = t1[j]
+					}
+					exists := false
+					for _, t := range rows {
+						if agree(t, nt, every) {
+							exists = true
+							break
+						}
+					}
+					if !exists {
+						rows = append(rows, nt)
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	lossless := false
+	tab := &Tableau{}
+	tab.Attrs = append(tab.Attrs, r.Attrs...)
+	for _, row := range rows {
+		srow := make([]string, ncols)
+		all := true
+		for j, v := range row {
+			if v == 0 {
+				srow[j] = fmt.Sprintf("a%d", j+1)
+				continue
+			}
+			all = false
+			srow[j] = fmt.Sprintf("b%d_%d", (v-1)/ncols+1, j+1)
+		}
+		if all {
+			lossless = true
+		}
+		tab.Rows = append(tab.Rows, srow)
+	}
+	return lossless, tab, nil
+}
+
+// checkParts returns an error if any of the attribute sets of a decomposition
+// has an attribute which is not in the Relation.
+func (r *Relation) checkParts(parts []AttrSet) error {
+	var unknown AttrSet
+	for _, p := range parts {
+		unknown.AddAll(p.Difference(r.Attrs))
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("relation has %d attributes (%v). decomposition has %d unknown attributes (%v)",
+			len(r.Attrs), r.Attrs, len(unknown), unknown)
+	}
+	return nil
+}
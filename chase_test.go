@@ -0,0 +1,64 @@
+package funcdep
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestLosslessJoinBinary checks the chase against the binary decomposition
+// rule: R1 and R2 join losslessly if R1 ∩ R2 determines R1 or R2.
+func TestLosslessJoinBinary(t *testing.T) {
+	rng := rand.New(rand.NewSource(15))
+	for i := 0; i < 500; i++ {
+		rel := randomRelation(rng, rng.Intn(6)+2, rng.Intn(8), 2)
+		var r1, r2 AttrSet
+		for _, a := range rel.Attrs {
+			switch rng.Intn(3) {
+			case 0:
+				r1 = append(r1, a)
+			case 1:
+				r2 = append(r2, a)
+			default:
+				r1 = append(r1, a)
+				r2 = append(r2, a)
+			}
+		}
+		common := r1.Intersection(r2)
+		closed := rel.AttrClosure(common)
+		want := closed.Contains(r1) || closed.Contains(r2)
+		if got, tab, _ := LosslessJoin(rel, []AttrSet{r1, r2}); got != want {
+			t.Fatalf("join of %s and %s under\n%s\ngot %v, want %v\n%s", r1, r2, rel, got, want, tab)
+		}
+	}
+}
+
+func TestLosslessJoinMVD(t *testing.T) {
+	rel, err := RelationFromString(`R(Course,Teacher,Book)
+Course ->> Teacher`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, tab, _ := LosslessJoin(rel, []AttrSet{{"Course", "Teacher"}, {"Course", "Book"}}); !ok {
+		t.Fatalf("split on Course ->> Teacher is lossy\n%s", tab)
+	}
+	if ok, tab, _ := LosslessJoin(rel, []AttrSet{{"Course", "Teacher"}, {"Teacher", "Book"}}); ok {
+		t.Fatalf("split on Teacher is lossless\n%s", tab)
+	}
+}
+
+func TestDecompositionUnknownAttrs(t *testing.T) {
+	rel, err := RelationFromString(`R(A,B,C,D,E)
+A --> B
+B,C --> D
+D --> E`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := []AttrSet{{"A", "B"}, {"B", "C", "Dx", "E"}}
+	if _, _, err := LosslessJoin(rel, parts); err == nil {
+		t.Error("LosslessJoin accepted unknown attribute Dx")
+	}
+	if _, _, err := PreservesDependencies(rel, parts); err == nil {
+		t.Error("PreservesDependencies accepted unknown attribute Dx")
+	}
+}
@@ -2,351 +2,185 @@
 package main
 
 import (
-	"bufio"
-	"compress/gzip"
-	"encoding/csv"
+	"context"
 	"flag"
 	"fmt"
-	"io"
-	"math/rand"
 	"os"
-	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/joiningdata/funcdep"
+	"github.com/joiningdata/funcdep/discover"
 )
 
-// DataSet represents data loaded from tabular files amd used to generate
-// functional dependencies.
-type DataSet struct {
-	skiplist map[int]string
-	header   []string
-	data     [][]string
-
-	rel *funcdep.Relation
-}
-
-// Sample a proportion of data records.
-func (ds *DataSet) Sample(rate float64) {
-	n := int(rate * float64(len(ds.data)))
-	rand.Shuffle(len(ds.data), func(i, j int) {
-		ds.data[i], ds.data[j] = ds.data[j], ds.data[i]
-	})
-	ds.data = ds.data[:n]
-}
+func main() {
+	sampleRate := flag.Float64("r", 1.0, "`ratio` of rows to sample for testing (0.0-1.0)")
+	excludeList := flag.String("x", "", "comma-separated list of `attributes` to exclude")
+	bruteForce := flag.Bool("bf", false, "also list candidate keys found by a brute-force search")
+	timeout := flag.Duration("timeout", 0, "stop candidate key searches after `duration` (0 for no limit)")
+	maxKey := flag.Int("maxkey", 0, "only search for candidate keys up to `n` attributes (0 for no limit)")
+	workers := flag.Int("j", runtime.NumCPU(), "use `n` workers for the brute-force candidate key search")
+	maxLHS := flag.Int("maxlhs", 0, "only find dependencies with up to `n` attributes on the left side (0 for no limit)")
+	algo := flag.String("algo", "levelwise", "discovery `algorithm` to use ("+strings.Join(discover.Algorithms(), ", ")+")")
+	maxError := flag.Float64("e", 0.0, "find approximate dependencies with up to this `ratio` of violating rows (0.0-1.0)")
+	why := flag.String("why", "", "list the rows which violate the functional dependency `fd`")
+	nearMiss := flag.Bool("nearmiss", false, "list the rows which violate each approximate dependency (with -e)")
+	maxGroups := flag.Int("groups", 10, "list at most `n` groups of violating rows for each dependency (0 for no limit)")
+	flag.Parse()
 
-// ReadData loads a DataSet, tracking the header along with the rows of data.
-// Supports both CSV and tab-delimited data files with a single-line header.
-func ReadData(filename string) (*DataSet, error) {
-	f, err := os.Open(filename)
-	if err != nil {
-		return nil, err
+	if *nearMiss && *maxError <= 0 {
+		fmt.Fprintln(os.Stderr, "-nearmiss needs approximate dependencies (use -e)")
+		os.Exit(1)
 	}
-	defer f.Close()
 
-	// support gzip transparently
-	r := io.Reader(f)
-	if strings.HasSuffix(filename, "gz") {
-		r, err = gzip.NewReader(f)
+	var whyFD *funcdep.FuncDep
+	if *why != "" {
+		fd, err := funcdep.FromString(*why)
 		if err != nil {
-			r = f
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
 		}
+		whyFD = fd
 	}
 
-	ext := filepath.Ext(filename)
-	relname := strings.TrimSuffix(filepath.Base(filename), ext)
-	ds := &DataSet{
-		skiplist: make(map[int]string),
-		rel: &funcdep.Relation{
-			Name: relname,
-		},
+	ds, err := discover.ReadData(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
 	}
-
-	if strings.ToLower(ext) == ".csv" {
-		rdr := csv.NewReader(r)
-		data, err := rdr.ReadAll()
-		if err != nil {
-			return nil, err
-		}
-		ds.header = data[0]
-		ds.data = data[1:]
-	} else {
-		haveHeader := false
-		s := bufio.NewScanner(r)
-		for s.Scan() {
-			row := strings.Split(s.Text(), "\t")
-			if !haveHeader {
-				ds.header = row
-				haveHeader = true
-				continue
+	if whyFD != nil {
+		// check the attributes before the (possibly slow) discovery
+		attrs := ds.Relation().Attrs
+		for _, a := range whyFD.Left.Union(whyFD.Right) {
+			if !attrs.Contains(funcdep.AttrSet{a}) {
+				fmt.Fprintf(os.Stderr, "unknown attribute '%s' in -why\n", a)
+				os.Exit(1)
 			}
-			ds.data = append(ds.data, row)
 		}
 	}
-
-	for i, h := range ds.header {
-		if h == "" {
-			ds.skiplist[i] = "(empty)"
-			continue
+	opts := discover.Options{
+		SampleRate: *sampleRate,
+		MaxLHS:     *maxLHS,
+		MaxError:   *maxError,
+		Algorithm:  *algo,
+	}
+	if *excludeList != "" {
+		for _, p := range strings.Split(*excludeList, ",") {
+			opts.Exclude.Add(funcdep.Attr(strings.TrimSpace(p)))
 		}
-		ds.rel.Attrs.Add(funcdep.Attr(h))
 	}
+	res, err := ds.Discover(opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	fmt.Printf("Loaded %d rows", res.Rows)
+	if res.SampledRows != res.Rows {
+		fmt.Printf("  Random sample using %d rows", res.SampledRows)
+	}
+	fmt.Println()
 
-	return ds, nil
-}
-
-// Analyze a dataset to determine functional dependencies.
-func (ds *DataSet) Analyze() {
-	// TODO: check more than just pairs of columns
-
-	chkagainst := func(ii int, jj []int) {
-		jx := len(jj)
-		mx := ii + 1
-		if jx > 0 {
-			mx = jj[jx-1]
-		}
-		jj = append(jj, mx)
-		for j := range ds.header {
-			if _, skip := ds.skiplist[j]; skip {
-				continue
-			}
-			if j >= mx {
-				jj[jx] = j
-				ds.CheckColumnPair(ii, jj)
-			}
+	rel := res.Relation
+	if *maxError > 0 {
+		fmt.Println("--- Dependencies (with g3 error)")
+		for _, dep := range res.Dependencies {
+			fmt.Println(dep)
 		}
 	}
+	fmt.Println("--- Pre-simplification")
+	fmt.Println(rel.String())
+	res.Simplify()
+	fmt.Println("--- Post-simplification")
 
-	// for every (ordered) pair of columns,
-	// examine dependency between values
-	for i := range ds.header {
-		if _, skip := ds.skiplist[i]; skip {
-			continue
-		}
-		// 1:1 pairings
-		chkagainst(i, nil)
+	fmt.Println(rel.String())
 
-		for j := range ds.header {
-			if _, skip := ds.skiplist[j]; skip {
-				continue
-			}
-			if j > i {
-				// 1:2 pairings
-				chkagainst(i, []int{j})
-				//ds.CheckColumnPair(i, []int{j})
-			}
-		}
+	fmt.Println("--- Minimal cover")
+	for _, fd := range rel.MinimalCover() {
+		fmt.Println(fd)
 	}
 
-	// all of the resulting functional dependencies are
-	// simple pairs A->B, A->C, etc. we want all the
-	// attributes on the "right" to be combined for
-	// each "left" attribute. A->BC etc
-	baseFDs := ds.rel.FuncDeps
-	ds.rel.FuncDeps = nil
-
-	newFDs := make(map[string]*funcdep.FuncDep)
-	for _, fd := range baseFDs {
-		key := fd.Left.String()
-		if xfd, ok := newFDs[key]; ok {
-			xfd.Right.AddAll(fd.Right)
-		} else {
-			nfd := &funcdep.FuncDep{}
-			nfd.Left.AddAll(fd.Left)
-			nfd.Right.AddAll(fd.Right)
-			newFDs[key] = nfd
-		}
+	fmt.Println("---")
+	fmt.Println("Candidate Keys:")
+	cks := rel.CandidateKeys()
+	if len(cks) == 0 {
+		cks = rel.CandidateKeysAlt()
 	}
-	for _, fd := range newFDs {
-		ds.rel.FuncDeps = append(ds.rel.FuncDeps, fd)
+	if len(cks) == 0 {
+		fmt.Println("No straightforward Candidate Keys")
 	}
-}
-
-// Simplify the functional dependencies.
-func (ds *DataSet) Simplify() {
-	// first, do any right-sides contain the closure of a different FD?
-	// e.g.    GeneID --> *GeneSymbol*
-	//         SNPID --> GeneID,*GeneSymbol*
-	//
-	// becomes SNPID --> GeneID
-	toremove := make(map[int]struct{})
-	for i, fd1 := range ds.rel.FuncDeps {
-		right := fd1.Right
-		for _, fd2 := range ds.rel.FuncDeps {
-			clo := fd2.Left.Union(fd2.Right)
-			if right.Contains(clo) {
-				for _, a := range fd2.Right {
-					right.Remove(a)
-				}
-			}
-		}
-		if len(right) == 0 {
-			toremove[i] = struct{}{}
-		}
-		fd1.Right = right
+	for _, ck := range cks {
+		fmt.Println("   ", ck)
 	}
 
-	// might have some duplicates after the above - remove them
-	for i, fd1 := range ds.rel.FuncDeps {
-		if len(fd1.Right) == 0 {
-			continue
-		}
-		for j, fd2 := range ds.rel.FuncDeps {
-			if i == j {
-				continue
-			}
-			if fd2.Left.Contains(fd1.Left) && fd2.Right.Contains(fd1.Right) {
-				toremove[j] = struct{}{}
-				break
-			}
-		}
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
 	}
+	lim := funcdep.KeySearch{MaxKeySize: *maxKey, Workers: *workers}
 
-	// TODO: more stuff here
+	fmt.Println("Candidate Keys (Lucchesi-Osborn):")
+	complete, err := rel.WalkCandidateKeysLO(ctx, lim, printKey)
+	printSearchStatus(complete, err)
 
-	newFDs := []*funcdep.FuncDep{}
-	for i, fd := range ds.rel.FuncDeps {
-		if _, ok := toremove[i]; !ok {
-			newFDs = append(newFDs, fd)
-		}
+	if *bruteForce {
+		fmt.Println("Candidate Keys (Brute-Force):")
+		complete, err = rel.WalkCandidateKeysBF(ctx, lim, printKey)
+		printSearchStatus(complete, err)
 	}
-	ds.rel.FuncDeps = newFDs
-}
-
-// CheckColumnPair counts data co-occurance for the two columns given.
-// If either column (or both) functionally determines the other, then
-// the relationship is recorded.
-func (ds *DataSet) CheckColumnPair(i int, js []int) {
-	// value_j => set of value_i
-	deps := make(map[string]map[string]struct{})
-
-	// value_i => set of value_j
-	revdeps := make(map[string]map[string]struct{})
-
-	// read through the data set and track values
-	// observed for each pair
-	for _, row := range ds.data {
-		vi := row[i]
-		vjs := []string{}
-		for _, j := range js {
-			vjs = append(vjs, row[j])
-		}
-		vj := strings.Join(vjs, "\t")
 
-		if _, ok := deps[vj]; !ok {
-			deps[vj] = map[string]struct{}{vi: struct{}{}}
-		} else {
-			deps[vj][vi] = struct{}{}
+	if whyFD != nil {
+		if err := printViolations(res, whyFD, *maxGroups); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
 		}
-
-		if _, ok := revdeps[vi]; !ok {
-			revdeps[vi] = map[string]struct{}{vj: struct{}{}}
-		} else {
-			revdeps[vi][vj] = struct{}{}
+	}
+	if *nearMiss {
+		for _, dep := range res.Dependencies {
+			if dep.Error == 0 {
+				continue
+			}
+			if err := printViolations(res, dep.FuncDep, *maxGroups); err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
 		}
 	}
+}
 
-	// if all vi are unique to each vj, then j -> i
-	uniqueValues := true
-	for _, vi := range deps {
-		if len(vi) > 1 {
-			uniqueValues = false
-			break
-		}
+// printViolations lists the groups of rows which break a functional dependency.
+func printViolations(res *discover.Result, fd *funcdep.FuncDep, maxGroups int) error {
+	conflicts, err := res.Violations(fd)
+	if err != nil {
+		return err
 	}
-	if uniqueValues {
-		fd := &funcdep.FuncDep{}
-		for _, j := range js {
-			fd.Left.Add(funcdep.Attr(ds.header[j]))
-		}
-		fd.Right.Add(funcdep.Attr(ds.header[i]))
-		ds.rel.FuncDeps = append(ds.rel.FuncDeps, fd)
+	nviol := 0
+	for _, c := range conflicts {
+		nviol += c.Violations()
 	}
-
-	///////
-
-	// if all vj are unique to each vi, then i -> j
-	uniqueValues = true
-	for _, vj := range revdeps {
-		if len(vj) > 1 {
-			uniqueValues = false
+	fmt.Printf("--- Violations of %s (%d rows in %d groups)\n", fd, nviol, len(conflicts))
+	for i, c := range conflicts {
+		if maxGroups > 0 && i == maxGroups {
+			fmt.Printf("    (%d more groups)\n", len(conflicts)-maxGroups)
 			break
 		}
+		fmt.Println(c)
 	}
-
-	if uniqueValues {
-		fd := &funcdep.FuncDep{}
-		fd.Left.Add(funcdep.Attr(ds.header[i]))
-		for _, j := range js {
-			fd.Right.Add(funcdep.Attr(ds.header[j]))
-		}
-		ds.rel.FuncDeps = append(ds.rel.FuncDeps, fd)
-	}
-
-	return
+	return nil
 }
 
-func main() {
-	sampleRate := flag.Float64("r", 1.0, "`ratio` of rows to sample for testing (0.0-1.0)")
-	excludeList := flag.String("x", "", "comma-separated list of `attributes` to exclude")
-	flag.Parse()
+// printKey lists a candidate key as soon as it is found.
+func printKey(ck funcdep.AttrSet) bool {
+	fmt.Println("   ", ck)
+	return true
+}
 
-	ds, err := ReadData(flag.Arg(0))
+// printSearchStatus notes if a candidate key search ended early.
+func printSearchStatus(complete bool, err error) {
 	if err != nil {
-		fmt.Fprintf(os.Stderr, err.Error())
-		os.Exit(1)
-	}
-	if *excludeList != "" {
-		parts := strings.Split(*excludeList, ",")
-		for j, p := range parts {
-			parts[j] = strings.TrimSpace(p)
-		}
-
-		for i, h := range ds.header {
-			for _, p := range parts {
-				if h == p {
-					ds.skiplist[i] = p
-					ds.rel.Attrs.Remove(funcdep.Attr(p))
-					break
-				}
-			}
-		}
-	}
-	fmt.Printf("Loaded %d rows", len(ds.data))
-	if *sampleRate > 0.0 && *sampleRate < 1.0 {
-		ds.Sample(*sampleRate)
-		fmt.Printf("  Random sample using %d rows", len(ds.data))
-	}
-	ds.Analyze()
-	fmt.Println("--- Pre-simplification")
-	fmt.Println(ds.rel.String())
-	ds.Simplify()
-	fmt.Println("--- Post-simplification")
-
-	fmt.Println(ds.rel.String())
-
-	fmt.Println("---")
-	fmt.Println("Candidate Keys:")
-	cks := ds.rel.CandidateKeys()
-	if len(cks) == 0 {
-		cks = ds.rel.CandidateKeysAlt()
-	}
-	if len(cks) == 0 {
-		fmt.Println("No straightforward Candidate Keys -- Need a brute-force search!")
-	}
-	best := len(ds.rel.Attrs)
-	for _, ck := range cks {
-		if len(ck) < best {
-			best = len(ck)
-		}
-		fmt.Println("   ", ck)
-	}
-
-	if best > 2 {
-		fmt.Println("Candidate Keys (Brute-Force):")
-		cks = ds.rel.CandidateKeysBF()
-		for _, ck := range cks {
-			fmt.Println("   ", ck)
-		}
+		fmt.Println("    (search stopped:", err.Error()+")")
+	} else if !complete {
+		fmt.Println("    (search incomplete: key size limit reached)")
 	}
 }
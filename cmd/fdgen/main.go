@@ -0,0 +1,288 @@
+// Command fdgen reads in functional dependencies and generates random tabular data that satisfies them.
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/joiningdata/funcdep"
+)
+
+// Generator produces random rows of data which satisfy the functional
+// dependencies of a relation.
+type Generator struct {
+	rel   *funcdep.Relation
+	cover []*funcdep.FuncDep
+	cols  map[funcdep.Attr]int
+	rng   *rand.Rand
+
+	// cardinality of each column (before dependencies are enforced)
+	card []int
+
+	// value ids for each row, a negative id is a null value
+	data [][]int
+}
+
+// NewGenerator creates a Generator for the relation using the given seed.
+func NewGenerator(rel *funcdep.Relation, seed int64, defaultCard int) *Generator {
+	g := &Generator{
+		rel:   rel,
+		cover: rel.MinimalCover(),
+		cols:  make(map[funcdep.Attr]int),
+		rng:   rand.New(rand.NewSource(seed)),
+		card:  make([]int, len(rel.Attrs)),
+	}
+	for j, a := range rel.Attrs {
+		g.cols[a] = j
+		g.card[j] = defaultCard
+	}
+	return g
+}
+
+// SetCardinality sets the number of distinct random values for the attribute.
+func (g *Generator) SetCardinality(a funcdep.Attr, n int) error {
+	j, ok := g.cols[a]
+	if !ok {
+		return fmt.Errorf("unknown attribute '%s'", a)
+	}
+	g.card[j] = n
+	return nil
+}
+
+// Generate n rows of random values, with a proportion of null values, such
+// that every functional dependency holds.
+//
+// Rows are added one at a time. For each dependency X->A the value of A is
+// looked up from an earlier row with the same X, if there is one. A row that
+// would need two different values for some A is drawn again, and if that
+// keeps failing a copy of an earlier row is used instead.
+func (g *Generator) Generate(n int, nullRate float64) {
+	const maxTries = 10
+
+	seen := make([]map[string]int, len(g.cover))
+	for i := range seen {
+		seen[i] = make(map[string]int)
+	}
+
+	g.data = make([][]int, 0, n)
+	for len(g.data) < n {
+		var row []int
+		for try := 0; try < maxTries && row == nil; try++ {
+			row = g.tryRow(seen, nullRate)
+		}
+		if row == nil {
+			row = append([]int(nil), g.data[g.rng.Intn(len(g.data))]...)
+		}
+		for i, fd := range g.cover {
+			seen[i][g.key(row, fd.Left)] = row[g.cols[fd.Right[0]]]
+		}
+		g.data = append(g.data, row)
+	}
+}
+
+// tryRow draws a random row and fills in the values determined by earlier
+// rows. Returns nil if the earlier rows disagree about some value.
+func (g *Generator) tryRow(seen []map[string]int, nullRate float64) []int {
+	row := make([]int, len(g.card))
+	for j, c := range g.card {
+		if g.rng.Float64() < nullRate {
+			row[j] = -1
+		} else {
+			row[j] = g.rng.Intn(c)
+		}
+	}
+
+	// each column can only be looked up once, so this always finishes
+	fixed := make([]bool, len(row))
+	for changed := true; changed; {
+		changed = false
+		for i, fd := range g.cover {
+			v, ok := seen[i][g.key(row, fd.Left)]
+			a := g.cols[fd.Right[0]]
+			if !ok || row[a] == v {
+				continue
+			}
+			if fixed[a] {
+				return nil
+			}
+			row[a] = v
+			fixed[a] = true
+			changed = true
+		}
+	}
+	return row
+}
+
+// AddNoise makes a proportion of rows violate a random functional dependency
+// X->A, by copying X from another row and giving A a new value. Rows are only
+// copied from rows without noise, so each noisy row conflicts with at least
+// one other row, and at least one row is always left without noise.
+func (g *Generator) AddNoise(rate float64) {
+	if len(g.data) < 2 || len(g.cover) == 0 {
+		return
+	}
+	n := int(rate * float64(len(g.data)))
+	if n > len(g.data)-1 {
+		n = len(g.data) - 1
+	}
+	perm := g.rng.Perm(len(g.data))
+	clean := perm[n:]
+	for k, i := range perm[:n] {
+		fd := g.cover[g.rng.Intn(len(g.cover))]
+		j := clean[g.rng.Intn(len(clean))]
+		for _, x := range fd.Left {
+			g.data[i][g.cols[x]] = g.data[j][g.cols[x]]
+		}
+		a := g.cols[fd.Right[0]]
+		g.data[i][a] = g.card[a] + k
+	}
+}
+
+// Write the generated rows with a single-line header, as CSV or tab-delimited data.
+func (g *Generator) Write(w io.Writer, tabs bool) error {
+	header := make([]string, len(g.rel.Attrs))
+	for j, a := range g.rel.Attrs {
+		header[j] = string(a)
+	}
+	cw := csv.NewWriter(w)
+	if tabs {
+		cw.Comma = '\t'
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	row := make([]string, len(header))
+	for _, vals := range g.data {
+		for j, v := range vals {
+			row[j] = ""
+			if v >= 0 {
+				row[j] = strconv.Itoa(v)
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// key joins the values of the attributes in a row, for use as a map key.
+func (g *Generator) key(row []int, attrs funcdep.AttrSet) string {
+	parts := make([]string, len(attrs))
+	for i, x := range attrs {
+		parts[i] = strconv.Itoa(row[g.cols[x]])
+	}
+	return strings.Join(parts, "\t")
+}
+
+func main() {
+	nosep := flag.Bool("n", false, "use single-character attribute names (no separator)")
+	delim := flag.String("d", ",", "use `separator` between attribute names")
+	nrows := flag.Int("rows", 1000, "`number` of rows to generate")
+	seed := flag.Int64("seed", 1, "random `seed` for reproducible output")
+	defCard := flag.Int("c", 100, "default `cardinality` of each column")
+	cards := flag.String("card", "", "comma-separated list of per-column cardinalities (`attr=n,...`)")
+	nullRate := flag.Float64("null", 0.0, "`ratio` of null values (0.0-1.0)")
+	noise := flag.Float64("noise", 0.0, "`ratio` of rows which violate a dependency (0.0-1.0)")
+	tsv := flag.Bool("tsv", false, "write tab-delimited data instead of CSV")
+	output := flag.String("o", "", "write to `file` instead of stdout")
+	flag.Parse()
+
+	var invalid string
+	switch {
+	case *nrows < 0:
+		invalid = "-rows must not be negative"
+	case *defCard < 1:
+		invalid = "-c must be at least 1"
+	case *nullRate < 0 || *nullRate > 1:
+		invalid = "-null must be between 0.0 and 1.0"
+	case *noise < 0 || *noise > 1:
+		invalid = "-noise must be between 0.0 and 1.0"
+	}
+	if invalid != "" {
+		fmt.Fprintln(os.Stderr, invalid)
+		os.Exit(1)
+	}
+
+	if *delim != "" {
+		funcdep.AttrSep = *delim
+	}
+	if *nosep {
+		funcdep.AttrSep = ""
+	}
+
+	var r io.ReadCloser = os.Stdin
+	if fn := flag.Arg(0); fn != "" {
+		f, err := os.Open(fn)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		r = f
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	r.Close()
+
+	rel, err := funcdep.RelationFromString(string(data))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	g := NewGenerator(rel, *seed, *defCard)
+	if *cards != "" {
+		for _, p := range strings.Split(*cards, ",") {
+			kv := strings.SplitN(p, "=", 2)
+			n := 0
+			if len(kv) == 2 {
+				n, err = strconv.Atoi(strings.TrimSpace(kv[1]))
+			}
+			if len(kv) != 2 || err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "invalid cardinality '%s'\n", p)
+				os.Exit(1)
+			}
+			if err = g.SetCardinality(funcdep.Attr(strings.TrimSpace(kv[0])), n); err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+		}
+	}
+	g.Generate(*nrows, *nullRate)
+	g.AddNoise(*noise)
+
+	var w io.WriteCloser = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	err = g.Write(bw, *tsv)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/joiningdata/funcdep"
+)
+
+const testRelation = `R(A,B,C,D,E,F)
+A --> B,C
+B,C --> D
+D,E --> F
+F --> A`
+
+// conflicts returns true if rows a and b agree on the left side of fd but
+// not on its right side.
+func (g *Generator) conflicts(fd *funcdep.FuncDep, a, b []int) bool {
+	return g.key(a, fd.Left) == g.key(b, fd.Left) && g.key(a, fd.Right) != g.key(b, fd.Right)
+}
+
+func TestGenerate(t *testing.T) {
+	rel, err := funcdep.RelationFromString(testRelation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seed := int64(1); seed <= 5; seed++ {
+		g := NewGenerator(rel, seed, 5)
+		g.Generate(300, 0.1)
+		if len(g.data) != 300 {
+			t.Fatalf("got %d rows, want 300", len(g.data))
+		}
+		for _, fd := range rel.MinimalCover() {
+			seen := make(map[string]string)
+			for _, row := range g.data {
+				l, r := g.key(row, fd.Left), g.key(row, fd.Right)
+				if v, ok := seen[l]; ok && v != r {
+					t.Fatalf("seed %d: rows break %s", seed, fd)
+				}
+				seen[l] = r
+			}
+		}
+	}
+}
+
+func TestAddNoise(t *testing.T) {
+	rel, err := funcdep.RelationFromString(testRelation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rate := range []float64{0, 0.05, 0.2, 0.5, 1} {
+		g := NewGenerator(rel, 7, 20)
+		g.Generate(200, 0)
+		orig := make([][]int, len(g.data))
+		for i, row := range g.data {
+			orig[i] = append([]int(nil), row...)
+		}
+		g.AddNoise(rate)
+
+		want := int(rate * 200)
+		if want > 199 {
+			want = 199
+		}
+		var clean, noisy [][]int
+		for i, row := range g.data {
+			if g.key(row, rel.Attrs) == g.key(orig[i], rel.Attrs) {
+				clean = append(clean, row)
+			} else {
+				noisy = append(noisy, row)
+			}
+		}
+		if len(noisy) != want {
+			t.Fatalf("rate %.2f: got %d noisy rows, want %d", rate, len(noisy), want)
+		}
+		// every noisy row breaks a dependency with some row without noise
+		for _, row := range noisy {
+			violates := false
+			for _, fd := range g.cover {
+				for _, c := range clean {
+					violates = violates || g.conflicts(fd, row, c)
+				}
+			}
+			if !violates {
+				t.Fatalf("rate %.2f: noisy row %v breaks no dependency", rate, row)
+			}
+		}
+	}
+}
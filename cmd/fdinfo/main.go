@@ -2,11 +2,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"runtime"
+	"strings"
 
 	"github.com/joiningdata/funcdep"
 )
@@ -14,6 +17,14 @@ import (
 func main() {
 	nosep := flag.Bool("n", false, "use single-character attribute names (no separator)")
 	delim := flag.String("d", ",", "use `separator` between attribute names")
+	bruteForce := flag.Bool("bf", false, "also list candidate keys found by a brute-force search")
+	timeout := flag.Duration("timeout", 0, "stop candidate key searches after `duration` (0 for no limit)")
+	maxKey := flag.Int("maxkey", 0, "only search for candidate keys up to `n` attributes (0 for no limit)")
+	workers := flag.Int("j", runtime.NumCPU(), "use `n` workers for the brute-force candidate key search")
+	armstrong := flag.String("armstrong", "", "write an Armstrong relation for the dependencies to CSV `file`")
+	explain := flag.String("explain", "", "prove that the relation implies the functional dependency `fd`")
+	bcnf := flag.Bool("bcnf", false, "list a BCNF decomposition and whether it preserves dependencies (may be slow)")
+	split := flag.String("split", "", "check a proposed decomposition into `relations` (attribute lists separated by ';')")
 	flag.Parse()
 
 	if *delim != "" {
@@ -46,23 +57,172 @@ func main() {
 		os.Exit(1)
 	}
 
+	// check the proposed decomposition up front, as a typo in an
+	// attribute name would otherwise give a misleading result.
+	var splitParts []funcdep.AttrSet
+	var splitLossless bool
+	var splitTab *funcdep.Tableau
+	if *split != "" {
+		for _, p := range strings.Split(*split, ";") {
+			var part funcdep.AttrSet
+			for _, a := range strings.Split(p, funcdep.AttrSep) {
+				part.Add(funcdep.Attr(strings.TrimSpace(a)))
+			}
+			splitParts = append(splitParts, part)
+		}
+		splitLossless, splitTab, err = funcdep.LosslessJoin(rel, splitParts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	}
+
 	fmt.Println(rel)
 
+	fmt.Println("Minimal Cover:")
+	for _, fd := range rel.MinimalCover() {
+		fmt.Println("   ", fd)
+	}
+
 	fmt.Println("Candidate Keys:")
 	cks := rel.CandidateKeys()
 	if len(cks) == 0 {
 		cks = rel.CandidateKeysAlt()
 	}
 	if len(cks) == 0 {
-		fmt.Println("No straightforward Candidate Keys -- Need a brute-force search!")
+		fmt.Println("No straightforward Candidate Keys")
 	}
 	for _, ck := range cks {
 		fmt.Println("   ", ck)
 	}
 
-	fmt.Println("Candidate Keys (Brute-Force):")
-	cks = rel.CandidateKeysBF()
-	for _, ck := range cks {
-		fmt.Println("   ", ck)
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	lim := funcdep.KeySearch{MaxKeySize: *maxKey, Workers: *workers}
+
+	fmt.Println("Candidate Keys (Lucchesi-Osborn):")
+	var keys []funcdep.AttrSet
+	complete, err := rel.WalkCandidateKeysLO(ctx, lim, func(ck funcdep.AttrSet) bool {
+		keys = append(keys, ck)
+		return printKey(ck)
+	})
+	printSearchStatus(complete, err)
+	// prime attributes and normal forms need every candidate key
+	keysComplete := complete && err == nil
+
+	if *bruteForce {
+		fmt.Println("Candidate Keys (Brute-Force):")
+		complete, err = rel.WalkCandidateKeysBF(ctx, lim, printKey)
+		printSearchStatus(complete, err)
+	}
+
+	left, right, both, neither := rel.AttrClasses()
+	fmt.Println("Attribute Classes:")
+	fmt.Println("    L: ", left)
+	fmt.Println("    R: ", right)
+	fmt.Println("    LR:", both)
+	fmt.Println("    N: ", neither)
+
+	if keysComplete {
+		var prime funcdep.AttrSet
+		prime.AddAll(keys...)
+		fmt.Println("Prime Attributes:", prime)
+		fmt.Println("Non-Prime Attributes:", rel.Attrs.Difference(prime))
+
+		nf, violations := rel.NormalFormWithKeys(keys)
+		fmt.Println("Normal Form:", nf)
+		for _, v := range violations {
+			fmt.Println("   ", v)
+		}
+	} else {
+		fmt.Println("Prime Attributes: (skipped: candidate key search incomplete)")
+		fmt.Println("Normal Form: (skipped: candidate key search incomplete)")
+	}
+
+	if len(rel.MVDs) > 0 {
+		fmt.Println("4NF Violations:")
+		for _, m := range rel.FourNFViolations() {
+			fmt.Println("   ", m)
+		}
+	}
+
+	if *bcnf {
+		fmt.Println("BCNF Decomposition:")
+		var parts []funcdep.AttrSet
+		for _, part := range rel.DecomposeBCNF() {
+			fmt.Println("   ", part.Name+"("+part.Attrs.String()+")")
+			parts = append(parts, part.Attrs)
+		}
+		printLostDeps(rel, parts)
+	}
+
+	if *split != "" {
+		fmt.Println("Proposed Decomposition:")
+		for _, part := range splitParts {
+			fmt.Println("   ", part)
+		}
+		fmt.Println("Lossless Join:", splitLossless)
+		fmt.Println(splitTab)
+		printLostDeps(rel, splitParts)
+	}
+
+	if *explain != "" {
+		fd, err := funcdep.FromString(*explain)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		fmt.Println("Proof of", fd.String()+":")
+		if proof, ok := funcdep.Explain(rel, fd); ok {
+			fmt.Println(proof)
+		} else {
+			fmt.Println("    not implied by the functional dependencies")
+		}
+	}
+
+	if *armstrong != "" {
+		f, err := os.Create(*armstrong)
+		if err == nil {
+			err = rel.WriteArmstrongCSV(f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	}
+}
+
+// printKey lists a candidate key as soon as it is found.
+func printKey(ck funcdep.AttrSet) bool {
+	fmt.Println("   ", ck)
+	return true
+}
+
+// printSearchStatus notes if a candidate key search ended early.
+func printSearchStatus(complete bool, err error) {
+	if err != nil {
+		fmt.Println("    (search stopped:", err.Error()+")")
+	} else if !complete {
+		fmt.Println("    (search incomplete: key size limit reached)")
+	}
+}
+
+// printLostDeps lists the dependencies that a decomposition does not preserve.
+func printLostDeps(rel *funcdep.Relation, parts []funcdep.AttrSet) {
+	ok, lost, err := funcdep.PreservesDependencies(rel, parts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	fmt.Println("Preserves Dependencies:", ok)
+	for _, fd := range lost {
+		fmt.Println("   ", fd)
 	}
 }
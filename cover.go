@@ -0,0 +1,134 @@
+package funcdep
+
+import "sort"
+
+// MinimalCover returns a minimal (canonical) cover of the functional
+// dependencies on this Relation. The result is equivalent to r.FuncDeps, but
+// every dependency has a single attribute on the right side, no extraneous
+// attributes on the left side, and no dependency is implied by the others.
+//
+// The cover is returned in sorted order so that equivalent inputs given in the
+// same order produce identical output.
+func (r *Relation) MinimalCover() []*FuncDep {
+	// split right sides into single attributes, dropping trivial
+	// dependencies (A->A) and duplicates along the way.
+	var fds []*FuncDep
+	seen := make(map[string]struct{})
+	for _, fd := range r.FuncDeps {
+		for _, a := range fd.Right {
+			if fd.Left.Contains(AttrSet{a}) {
+				continue
+			}
+			nfd := &FuncDep{}
+			nfd.Left.AddAll(fd.Left)
+			nfd.Right.Add(a)
+			key := nfd.String()
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			fds = append(fds, nfd)
+		}
+	}
+	sortFuncDeps(fds)
+
+	ix := r.Index()
+	c := &closer{ix: ix, fds: make([]bitFD, len(fds))}
+	for i, fd := range fds {
+		c.fds[i] = bitFD{left: ix.Bits(fd.Left), right: ix.Bits(fd.Right)}
+	}
+
+	// remove extraneous attributes from the left sides.
+	// an attribute B is extraneous in XB->A if X->A is implied.
+	for i := range c.fds {
+		fd := &c.fds[i]
+		fd.left.Clone().Each(func(b int) {
+			smaller := fd.left.Clone()
+			smaller.Clear(b)
+			if c.closure(smaller).Contains(fd.right) {
+				fd.left = smaller
+				c.uses = nil
+			}
+		})
+	}
+
+	// reducing left sides may have introduced duplicates
+	var reduced []bitFD
+	for i, fd := range c.fds {
+		dup := false
+		for _, other := range c.fds[:i] {
+			if fd.left.Equal(other.left) && fd.right.Equal(other.right) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			reduced = append(reduced, fd)
+		}
+	}
+
+	// remove redundant dependencies, i.e. those implied by the rest.
+	for i := 0; i < len(reduced); {
+		rest := &closer{ix: ix}
+		rest.fds = append(rest.fds, reduced[:i]...)
+		rest.fds = append(rest.fds, reduced[i+1:]...)
+		if rest.closure(reduced[i].left).Contains(reduced[i].right) {
+			reduced = append(reduced[:i], reduced[i+1:]...)
+			continue
+		}
+		i++
+	}
+
+	fds = make([]*FuncDep, len(reduced))
+	for i, fd := range reduced {
+		fds[i] = &FuncDep{Left: ix.AttrSet(fd.left), Right: ix.AttrSet(fd.right)}
+	}
+	sortFuncDeps(fds)
+	return fds
+}
+
+// Implies returns true if the functional dependency fd is implied by the
+// functional dependencies on this Relation (using Armstrong's axioms).
+func (r *Relation) Implies(fd *FuncDep) bool {
+	return r.newCloser().implies(fd)
+}
+
+// Equivalent determines if two sets of functional dependencies are equivalent,
+// that is each set implies every dependency in the other. The dependencies in
+// a that are not implied by b are returned in aOnly, and the dependencies in b
+// that are not implied by a are returned in bOnly.
+func Equivalent(a, b []*FuncDep) (ok bool, aOnly, bOnly []*FuncDep) {
+	// index every attribute up front, so that each side is only
+	// prepared once for all of its closures.
+	var attrs AttrSet
+	for _, fd := range a {
+		attrs.AddAll(fd.Left, fd.Right)
+	}
+	for _, fd := range b {
+		attrs.AddAll(fd.Left, fd.Right)
+	}
+	ca := (&Relation{Attrs: attrs, FuncDeps: a}).newCloser()
+	cb := (&Relation{Attrs: attrs, FuncDeps: b}).newCloser()
+	for _, fd := range a {
+		if !cb.implies(fd) {
+			aOnly = append(aOnly, fd)
+		}
+	}
+	for _, fd := range b {
+		if !ca.implies(fd) {
+			bOnly = append(bOnly, fd)
+		}
+	}
+	return len(aOnly) == 0 && len(bOnly) == 0, aOnly, bOnly
+}
+
+// sortFuncDeps sorts a list of functional dependencies by their string form.
+func sortFuncDeps(fds []*FuncDep) {
+	keys := make(map[*FuncDep]string, len(fds))
+	for _, fd := range fds {
+		keys[fd] = fd.String()
+	}
+	sort.Slice(fds, func(i, j int) bool {
+		return keys[fds[i]] < keys[fds[j]]
+	})
+}
@@ -0,0 +1,83 @@
+package funcdep
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestMinimalCover(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		rel := randomRelation(rng, rng.Intn(7)+1, rng.Intn(10), 3)
+		cover := rel.MinimalCover()
+		if ok, lost, extra := Equivalent(rel.FuncDeps, cover); !ok {
+			t.Fatalf("cover of\n%s\nlost %v and added %v", rel, lost, extra)
+		}
+		for j, fd := range cover {
+			if len(fd.Right) != 1 || fd.Left.Contains(fd.Right) {
+				t.Fatalf("cover of\n%s\nhas %s", rel, fd)
+			}
+			others := &Relation{Attrs: rel.Attrs}
+			others.FuncDeps = append(others.FuncDeps, cover[:j]...)
+			others.FuncDeps = append(others.FuncDeps, cover[j+1:]...)
+			if others.Implies(fd) {
+				t.Fatalf("cover of\n%s\nhas redundant %s", rel, fd)
+			}
+			for _, a := range fd.Left {
+				smaller := &FuncDep{Left: fd.Left.Difference(AttrSet{a}), Right: fd.Right}
+				if rel.Implies(smaller) {
+					t.Fatalf("cover of\n%s\nhas extraneous %s in %s", rel, a, fd)
+				}
+			}
+		}
+	}
+}
+
+func TestMinimalCoverExample(t *testing.T) {
+	rel, err := RelationFromString(`R(A,B,C,D)
+A --> B,C
+B --> C
+A,B --> D
+A --> A`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, fd := range rel.MinimalCover() {
+		got = append(got, fd.String())
+	}
+	want := []string{"A --> B", "A --> D", "B --> C"}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	parse := func(lines ...string) []*FuncDep {
+		var fds []*FuncDep
+		for _, s := range lines {
+			fd, err := FromString(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fds = append(fds, fd)
+		}
+		return fds
+	}
+	a := parse("A --> B", "B --> C", "A --> D")
+	b := parse("A --> B,C", "B --> C", "E --> D")
+	ok, aOnly, bOnly := Equivalent(a, b)
+	if ok || len(aOnly) != 1 || aOnly[0].String() != "A --> D" ||
+		len(bOnly) != 1 || bOnly[0].String() != "E --> D" {
+		t.Fatalf("got %v, a only %v, b only %v", ok, aOnly, bOnly)
+	}
+
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 200; i++ {
+		rel := randomRelation(rng, rng.Intn(40)+1, rng.Intn(60), 3)
+		if ok, aOnly, bOnly := Equivalent(rel.FuncDeps, rel.MinimalCover()); !ok {
+			t.Fatalf("cover of\n%s\nis not equivalent: %v, %v", rel, aOnly, bOnly)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package funcdep
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DecomposeBCNF splits the Relation into a set of relations in Boyce-Codd
+// normal form. Each relation carries a minimal cover of the functional
+// dependencies projected onto its attributes, and is named after the original
+// relation (e.g. R_1, R_2, ...).
+//
+// The decomposition always has a lossless join, but some functional
+// dependencies may no longer be enforceable within a single relation.
+func (r *Relation) DecomposeBCNF() []*Relation {
+	var done []*Relation
+	work := []*Relation{r.Project(r.Attrs)}
+	for len(work) > 0 {
+		s := work[0]
+		work = work[1:]
+
+		v := s.bcnfViolation()
+		if v == nil {
+			done = append(done, s)
+			continue
+		}
+
+		// split S on the violation X->Y into (X⁺ ∩ S) and X ∪ (S - X⁺).
+		// both contain X, and X is a key of the first, so the join is lossless.
+		clo := s.AttrClosure(v.Left).Intersection(s.Attrs)
+		rest := v.Left.Union(s.Attrs.Difference(clo))
+		work = append([]*Relation{s.Project(clo), s.Project(rest)}, work...)
+	}
+	for i, s := range done {
+		s.Name = fmt.Sprintf("%s_%d", r.Name, i+1)
+	}
+	return done
+}
+
+// SynthesizeThreeNF builds a set of relations in third normal form using
+// Bernstein's synthesis algorithm. One relation is created for each distinct
+// left side in the minimal cover, relations that are contained within another
+// are merged into it, and a relation holding a candidate key is added if no
+// synthesized relation already contains one.
+//
+// The result has a lossless join and preserves every functional dependency.
+func (r *Relation) SynthesizeThreeNF() []*Relation {
+	var parts []*Relation
+	byLeft := make(map[string]*Relation)
+	for _, fd := range r.MinimalCover() {
+		key := fd.Left.String()
+		s, ok := byLeft[key]
+		if !ok {
+			s = &Relation{}
+			s.Attrs.AddAll(fd.Left)
+			byLeft[key] = s
+			parts = append(parts, s)
+		}
+		s.Attrs.AddAll(fd.Right)
+		s.FuncDeps = append(s.FuncDeps, fd)
+	}
+
+	// merge relations whose attributes are contained in another relation.
+	// larger relations are considered first, so that every contained
+	// relation is merged into one which is kept.
+	order := make([]int, len(parts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(parts[order[i]].Attrs) > len(parts[order[j]].Attrs)
+	})
+	keep := make([]bool, len(parts))
+	for _, i := range order {
+		s := parts[i]
+		var into *Relation
+		for j, other := range parts {
+			if keep[j] && other.Attrs.Contains(s.Attrs) {
+				into = other
+				break
+			}
+		}
+		if into == nil {
+			keep[i] = true
+			continue
+		}
+		into.FuncDeps = append(into.FuncDeps, s.FuncDeps...)
+	}
+	var merged []*Relation
+	for i, s := range parts {
+		if keep[i] {
+			merged = append(merged, s)
+		}
+	}
+	parts = merged
+
+	c := r.newCloser()
+	hasKey := false
+	for _, s := range parts {
+		if c.isSuperkey(c.ix.Bits(s.Attrs)) {
+			hasKey = true
+			break
+		}
+	}
+	if !hasKey {
+		// any one candidate key will do
+		s := &Relation{Attrs: c.ix.AttrSet(c.minimizeKey(c.all))}
+		parts = append(parts, s)
+	}
+
+	for i, s := range parts {
+		s.Name = fmt.Sprintf("%s_%d", r.Name, i+1)
+		sortAttrs(s.Attrs)
+		sortFuncDeps(s.FuncDeps)
+	}
+	return parts
+}
+
+// PreservesDependencies checks if the functional dependencies of r can be
+// enforced on a decomposition into relations over the given attribute sets,
+// i.e. if the union of the dependencies projected onto each set implies
+// every dependency of r. The dependencies which are lost are returned.
+//
+// The projections are never computed: for each X->Y, the attributes reachable
+// from X are grown by taking (Z ∩ Ri)⁺ ∩ Ri over every set Ri until a
+// fixpoint, which is then checked to contain Y.
+//
+// An error is returned if any of the attribute sets has an attribute which
+// is not in r.
+func PreservesDependencies(r *Relation, parts []AttrSet) (bool, []*FuncDep, error) {
+	if err := r.checkParts(parts); err != nil {
+		return false, nil, err
+	}
+	c := r.newCloser()
+	bparts := make([]Bitset, len(parts))
+	for i, p := range parts {
+		bparts[i] = c.ix.Bits(p)
+	}
+
+	var lost []*FuncDep
+	for i, fd := range r.FuncDeps {
+		z := c.fds[i].left.Clone()
+		for changed := true; changed; {
+			changed = false
+			for _, p := range bparts {
+				t := c.closure(z.Intersection(p)).Intersection(p)
+				if !z.Contains(t) {
+					z.UnionWith(t)
+					changed = true
+				}
+			}
+		}
+		if !z.Contains(c.fds[i].right) {
+			lost = append(lost, fd)
+		}
+	}
+	return len(lost) == 0, lost, nil
+}
+
+// bcnfViolation returns the first functional dependency on the Relation with
+// a left side that is not a superkey, or nil if the Relation is in BCNF.
+// It assumes the functional dependencies are a minimal cover.
+func (r *Relation) bcnfViolation() *FuncDep {
+	c := r.newCloser()
+	for _, fd := range r.FuncDeps {
+		if !c.isSuperkey(c.ix.Bits(fd.Left)) {
+			return fd
+		}
+	}
+	return nil
+}
+
+// Project returns a new Relation over the attributes in s, with a minimal
+// cover of every functional dependency that holds on s. This is the same
+// result as taking the closure of every subset of s, but it also keeps
+// dependencies that are only implied through attributes outside of s, which
+// filtering the existing FuncDeps by attribute would silently drop.
+//
+// Multivalued dependencies X ->> Y with X in s are kept as X ->> (Y ∩ s).
+func (r *Relation) Project(s AttrSet) *Relation {
+	res := &Relation{Name: r.Name}
+	res.Attrs.AddAll(s)
+	sortAttrs(res.Attrs)
+	res.FuncDeps = r.projectFDs(s)
+	for _, m := range r.MVDs {
+		if !s.Contains(m.Left) {
+			continue
+		}
+		right := m.Right.Intersection(s).Difference(m.Left)
+		if len(right) == 0 || len(m.Left.Union(right)) == len(res.Attrs) {
+			continue
+		}
+		sortAttrs(right)
+		pm := &MVD{}
+		pm.Left.AddAll(m.Left)
+		pm.Right = right
+		res.MVDs = append(res.MVDs, pm)
+	}
+	return res
+}
+
+// projectFDs computes a minimal cover of the functional dependencies implied
+// on the attribute set s. Attributes outside of s are eliminated one at a time
+// by resolution: for every pair X->A and YA->B, the dependency XY->B is added,
+// after which every dependency mentioning A is dropped. Unlike filtering the
+// existing dependencies, this keeps those implied through removed attributes.
+func (r *Relation) projectFDs(s AttrSet) []*FuncDep {
+	fds := r.MinimalCover()
+
+	var all AttrSet
+	all.AddAll(r.Attrs)
+	for _, fd := range fds {
+		all.AddAll(fd.Left, fd.Right)
+	}
+	sortAttrs(all)
+
+	for _, a := range all {
+		if s.Contains(AttrSet{a}) {
+			continue
+		}
+		var into, from, next []*FuncDep
+		for _, fd := range fds {
+			switch {
+			case fd.Right[0] == a:
+				into = append(into, fd)
+			case fd.Left.Contains(AttrSet{a}):
+				from = append(from, fd)
+			default:
+				next = append(next, fd)
+			}
+		}
+		for _, x := range into {
+			for _, y := range from {
+				left := x.Left.Union(y.Left.Difference(AttrSet{a}))
+				if left.Contains(y.Right) {
+					continue
+				}
+				nfd := &FuncDep{Left: left}
+				nfd.Right.AddAll(y.Right)
+				next = append(next, nfd)
+			}
+		}
+		fds = (&Relation{FuncDeps: next}).MinimalCover()
+	}
+	return fds
+}
+
+// sortAttrs sorts the attribute set in place.
+func sortAttrs(s AttrSet) {
+	sort.Slice(s, func(i, j int) bool {
+		return s[i] < s[j]
+	})
+}
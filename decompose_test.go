@@ -0,0 +1,156 @@
+package funcdep
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	rng := rand.New(rand.NewSource(17))
+	for i := 0; i < 300; i++ {
+		rel := randomRelation(rng, rng.Intn(7)+1, rng.Intn(10), 3)
+		var s AttrSet
+		for _, a := range rel.Attrs {
+			if rng.Intn(2) == 0 {
+				s = append(s, a)
+			}
+		}
+		proj := rel.Project(s)
+		if !sameSet(proj.Attrs, s) {
+			t.Fatalf("projection of\n%s\nonto %s has attributes %s", rel, s, proj.Attrs)
+		}
+		for _, fd := range proj.FuncDeps {
+			if !s.Contains(fd.Left.Union(fd.Right)) || !rel.Implies(fd) {
+				t.Fatalf("projection of\n%s\nonto %s has %s", rel, s, fd)
+			}
+		}
+
+		// every subset of s must keep its closure within s
+		for mask := 0; mask < 1<<uint(len(s)); mask++ {
+			var x AttrSet
+			for j, a := range s {
+				if mask&(1<<uint(j)) != 0 {
+					x = append(x, a)
+				}
+			}
+			want := rel.AttrClosure(x).Intersection(s)
+			if got := proj.AttrClosure(x); !sameSet(got, want) {
+				t.Fatalf("projection of\n%s\nonto %s gives %s+ = %s, want %s", rel, s, x, got, want)
+			}
+		}
+	}
+}
+
+func TestProjectTransitive(t *testing.T) {
+	rel, err := RelationFromString(`R(A,B,C)
+A --> B
+B --> C`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proj := rel.Project(AttrSet{"A", "C"})
+	if len(proj.FuncDeps) != 1 || proj.FuncDeps[0].String() != "A --> C" {
+		t.Fatalf("got projection\n%s", proj)
+	}
+}
+
+func TestSynthesizeThreeNF(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	for i := 0; i < 300; i++ {
+		rel := randomRelation(rng, rng.Intn(7)+1, rng.Intn(8), 3)
+		var parts []AttrSet
+		var all AttrSet
+		for j, part := range rel.SynthesizeThreeNF() {
+			if want := fmt.Sprintf("R_%d", j+1); part.Name != want {
+				t.Fatalf("part %d of\n%s\nis named %s, want %s", j+1, rel, part.Name, want)
+			}
+			if nf, vs := rel.Project(part.Attrs).NormalForm(); nf < ThirdNF {
+				t.Fatalf("part %s of\n%s\nis only in %s: %v", part.Attrs, rel, nf, vs)
+			}
+			parts = append(parts, part.Attrs)
+			all.AddAll(part.Attrs)
+		}
+		if !sameSet(all, rel.Attrs) {
+			t.Fatalf("3NF synthesis %v of\n%s\ndoes not cover the attributes", parts, rel)
+		}
+		if ok, tab, _ := LosslessJoin(rel, parts); !ok {
+			t.Fatalf("3NF synthesis %v of\n%s\nis lossy\n%s", parts, rel, tab)
+		}
+		if ok, lost, _ := PreservesDependencies(rel, parts); !ok {
+			t.Fatalf("3NF synthesis %v of\n%s\nloses %v", parts, rel, lost)
+		}
+	}
+}
+
+func TestPreservesDependencies(t *testing.T) {
+	rng := rand.New(rand.NewSource(16))
+	for i := 0; i < 500; i++ {
+		rel := randomRelation(rng, rng.Intn(6)+1, rng.Intn(8), 3)
+		var parts []AttrSet
+		for n := rng.Intn(3) + 1; n > 0; n-- {
+			var part AttrSet
+			for _, a := range rel.Attrs {
+				if rng.Intn(2) == 0 {
+					part = append(part, a)
+				}
+			}
+			parts = append(parts, part)
+		}
+
+		// project onto each part by taking the closure of all its subsets
+		proj := &Relation{Attrs: rel.Attrs}
+		for _, part := range parts {
+			for mask := 0; mask < 1<<uint(len(part)); mask++ {
+				var x AttrSet
+				for j, a := range part {
+					if mask&(1<<uint(j)) != 0 {
+						x = append(x, a)
+					}
+				}
+				proj.FuncDeps = append(proj.FuncDeps, &FuncDep{Left: x, Right: rel.AttrClosure(x).Intersection(part)})
+			}
+		}
+		var want []string
+		for _, fd := range rel.FuncDeps {
+			if !proj.Implies(fd) {
+				want = append(want, fd.String())
+			}
+		}
+
+		ok, lost, err := PreservesDependencies(rel, parts)
+		var got []string
+		for _, fd := range lost {
+			got = append(got, fd.String())
+		}
+		if err != nil || ok != (len(want) == 0) || strings.Join(got, "; ") != strings.Join(want, "; ") {
+			t.Fatalf("decomposition %v of\n%s\ngot %v lost %v (%v), want lost %v", parts, rel, ok, got, err, want)
+		}
+	}
+}
+
+func TestDecomposeBCNF(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for i := 0; i < 200; i++ {
+		rel := randomRelation(rng, rng.Intn(6)+2, rng.Intn(8), 2)
+		var parts []AttrSet
+		var all AttrSet
+		for j, part := range rel.DecomposeBCNF() {
+			if want := fmt.Sprintf("R_%d", j+1); part.Name != want {
+				t.Fatalf("part %d of\n%s\nis named %s, want %s", j+1, rel, part.Name, want)
+			}
+			if v := rel.Project(part.Attrs).bcnfViolation(); v != nil {
+				t.Fatalf("part %s of\n%s\nviolates BCNF with %s", part.Attrs, rel, v)
+			}
+			parts = append(parts, part.Attrs)
+			all.AddAll(part.Attrs)
+		}
+		if !sameSet(all, rel.Attrs) {
+			t.Fatalf("BCNF decomposition %v of\n%s\ndoes not cover the attributes", parts, rel)
+		}
+		if ok, tab, _ := LosslessJoin(rel, parts); !ok {
+			t.Fatalf("BCNF decomposition %v of\n%s\nis lossy\n%s", parts, rel, tab)
+		}
+	}
+}
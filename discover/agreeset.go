@@ -0,0 +1,149 @@
+package discover
+
+import (
+	"sort"
+
+	"github.com/joiningdata/funcdep"
+)
+
+// AgreeSet finds the minimal functional dependencies which hold on the data
+// by comparing rows, in the style of Dep-Miner and FastFDs.
+//
+// For every pair of rows, the set of columns on which they differ is
+// recorded. X->A holds when every pair of rows which differs on A also
+// differs somewhere in X, so the minimal left sides for A are the minimal
+// sets of columns which intersect all of those difference sets.
+//
+// The number of pairs grows with the square of the number of rows, so this
+// works best with few rows and many columns.
+type AgreeSet struct {
+	// MaxLHS limits left sides to this many columns, if greater than 0.
+	MaxLHS int
+}
+
+// Discover implements the Discoverer interface.
+func (as AgreeSet) Discover(header []string, rows [][]string, cols []int) []*Dependency {
+	ncols := len(header)
+	all := funcdep.NewBitset(ncols)
+	vals := make([][]int, ncols)
+	for _, j := range cols {
+		all.Set(j)
+		vals[j] = columnValues(rows, j)
+	}
+
+	// only pairs of rows in the same class of some column agree anywhere.
+	// each pair is compared once, in the first column where they agree.
+	diffs := make(map[string]funcdep.Bitset)
+	npairs := 0
+	for p, j := range cols {
+		for _, c := range valuePartition(vals[j]).classes {
+			for x, t1 := range c {
+				for _, t2 := range c[x+1:] {
+					seen := false
+					for _, k := range cols[:p] {
+						if vals[k][t1] == vals[k][t2] {
+							seen = true
+							break
+						}
+					}
+					if seen {
+						continue
+					}
+					npairs++
+					d := funcdep.NewBitset(ncols)
+					for _, k := range cols[:p] {
+						d.Set(k)
+					}
+					for _, k := range cols[p+1:] {
+						if vals[k][t1] != vals[k][t2] {
+							d.Set(k)
+						}
+					}
+					diffs[setKey(d)] = d
+				}
+			}
+		}
+	}
+	if n := len(rows); npairs < n*(n-1)/2 {
+		diffs[setKey(all)] = all
+	}
+
+	var res []*Dependency
+	for _, a := range cols {
+		var edges []funcdep.Bitset
+		possible := true
+		for _, d := range diffs {
+			if !d.Has(a) {
+				continue
+			}
+			e := without(d, a)
+			if e.Empty() {
+				// two rows differ only on a
+				possible = false
+				break
+			}
+			edges = append(edges, e)
+		}
+		if !possible {
+			continue
+		}
+		for _, left := range minimalTransversals(minimalSets(edges), as.MaxLHS) {
+			res = append(res, newDependency(header, left, a, 0, len(rows)))
+		}
+	}
+	return res
+}
+
+// minimalTransversals finds the minimal sets which intersect every edge, of
+// at most maxLHS positions if maxLHS is greater than 0. Uses Berge's method
+// of adding one edge at a time.
+func minimalTransversals(edges []funcdep.Bitset, maxLHS int) []funcdep.Bitset {
+	trans := []funcdep.Bitset{nil}
+	for _, e := range edges {
+		var next []funcdep.Bitset
+		for _, t := range trans {
+			if t.Intersects(e) {
+				next = append(next, t)
+				continue
+			}
+			if maxLHS > 0 && t.Len() >= maxLHS {
+				continue
+			}
+			e.Each(func(i int) {
+				x := t.Clone()
+				x.Set(i)
+				next = append(next, x)
+			})
+		}
+		trans = minimalSets(next)
+	}
+	return trans
+}
+
+// minimalSets removes duplicates and any set containing another, and sorts
+// the rest from smallest to largest.
+func minimalSets(sets []funcdep.Bitset) []funcdep.Bitset {
+	sort.SliceStable(sets, func(i, j int) bool {
+		return sets[i].Len() < sets[j].Len()
+	})
+	var res []funcdep.Bitset
+	for _, s := range sets {
+		min := true
+		for _, r := range res {
+			if s.Contains(r) {
+				min = false
+				break
+			}
+		}
+		if min {
+			res = append(res, s)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Len() != res[j].Len() {
+			return res[i].Len() < res[j].Len()
+		}
+		return setKey(res[i]) < setKey(res[j])
+	})
+	return res
+}
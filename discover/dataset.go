@@ -0,0 +1,366 @@
+package discover
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joiningdata/funcdep"
+)
+
+// DataSet represents data loaded from tabular files amd used to generate
+// functional dependencies.
+type DataSet struct {
+	skiplist map[int]string
+	header   []string
+	data     [][]string
+
+	// rownum is the original row number of each row of data, counting
+	// from 1 after the header.
+	rownum []int
+
+	rel *funcdep.Relation
+}
+
+// Sample a proportion of data records.
+func (ds *DataSet) Sample(rate float64) {
+	n := int(rate * float64(len(ds.data)))
+	rand.Shuffle(len(ds.data), func(i, j int) {
+		ds.data[i], ds.data[j] = ds.data[j], ds.data[i]
+		ds.rownum[i], ds.rownum[j] = ds.rownum[j], ds.rownum[i]
+	})
+	ds.data = ds.data[:n]
+	ds.rownum = ds.rownum[:n]
+}
+
+// ReadData loads a DataSet, tracking the header along with the rows of data.
+// Supports both CSV and tab-delimited data files with a single-line header.
+func ReadData(filename string) (*DataSet, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// support gzip transparently
+	r := io.Reader(f)
+	if strings.HasSuffix(filename, "gz") {
+		r, err = gzip.NewReader(f)
+		if err != nil {
+			r = f
+		}
+	}
+
+	ext := filepath.Ext(filename)
+	relname := strings.TrimSuffix(filepath.Base(filename), ext)
+
+	var header []string
+	var data [][]string
+	if strings.ToLower(ext) == ".csv" {
+		rdr := csv.NewReader(r)
+		data, err = rdr.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		header = data[0]
+		data = data[1:]
+	} else {
+		haveHeader := false
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			row := strings.Split(s.Text(), "\t")
+			if !haveHeader {
+				header = row
+				haveHeader = true
+				continue
+			}
+			data = append(data, row)
+		}
+	}
+
+	return NewDataSet(relname, header, data), nil
+}
+
+// NewDataSet creates a DataSet from rows of data already in memory. Columns
+// with an empty header are skipped.
+func NewDataSet(name string, header []string, data [][]string) *DataSet {
+	ds := &DataSet{
+		skiplist: make(map[int]string),
+		header:   header,
+		data:     data,
+		rownum:   make([]int, len(data)),
+		rel: &funcdep.Relation{
+			Name: name,
+		},
+	}
+	for i := range ds.rownum {
+		ds.rownum[i] = i + 1
+	}
+
+	for i, h := range ds.header {
+		if h == "" {
+			ds.skiplist[i] = "(empty)"
+			continue
+		}
+		ds.rel.Attrs.Add(funcdep.Attr(h))
+	}
+	return ds
+}
+
+// Analyze a dataset to determine functional dependencies, using the given
+// discovery algorithm. The dependencies are returned as found, along with
+// their errors, and replace any from an earlier call in the Relation.
+func (ds *DataSet) Analyze(d Discoverer) []*Dependency {
+	ds.rel.FuncDeps = nil
+	var cols []int
+	for i := range ds.header {
+		if _, skip := ds.skiplist[i]; skip {
+			continue
+		}
+		cols = append(cols, i)
+	}
+	deps := d.Discover(ds.header, ds.data, cols)
+	for _, dep := range deps {
+		ds.rel.FuncDeps = append(ds.rel.FuncDeps, dep.FuncDep)
+	}
+
+	// we want all the attributes on the "right" to be
+	// combined for each "left" attribute. A->BC etc
+	ds.mergeLeftSides()
+	return deps
+}
+
+// mergeLeftSides combines functional dependencies with the same left side,
+// e.g. A->B and A->C become A->B,C
+func (ds *DataSet) mergeLeftSides() {
+	baseFDs := ds.rel.FuncDeps
+	ds.rel.FuncDeps = nil
+
+	newFDs := make(map[string]*funcdep.FuncDep)
+	for _, fd := range baseFDs {
+		key := fd.Left.String()
+		if xfd, ok := newFDs[key]; ok {
+			xfd.Right.AddAll(fd.Right)
+		} else {
+			nfd := &funcdep.FuncDep{}
+			nfd.Left.AddAll(fd.Left)
+			nfd.Right.AddAll(fd.Right)
+			newFDs[key] = nfd
+			ds.rel.FuncDeps = append(ds.rel.FuncDeps, nfd)
+		}
+	}
+}
+
+// Simplify the functional dependencies.
+func (ds *DataSet) Simplify() {
+	// first, do any right-sides contain the closure of a different FD?
+	// e.g.    GeneID --> *GeneSymbol*
+	//         SNPID --> GeneID,*GeneSymbol*
+	//
+	// becomes SNPID --> GeneID
+	toremove := make(map[int]struct{})
+	for i, fd1 := range ds.rel.FuncDeps {
+		right := fd1.Right
+		for _, fd2 := range ds.rel.FuncDeps {
+			clo := fd2.Left.Union(fd2.Right)
+			if right.Contains(clo) {
+				for _, a := range fd2.Right {
+					right.Remove(a)
+				}
+			}
+		}
+		if len(right) == 0 {
+			toremove[i] = struct{}{}
+		}
+		fd1.Right = right
+	}
+
+	// might have some duplicates after the above - remove them
+	for i, fd1 := range ds.rel.FuncDeps {
+		if len(fd1.Right) == 0 {
+			continue
+		}
+		for j, fd2 := range ds.rel.FuncDeps {
+			if i == j {
+				continue
+			}
+			if fd2.Left.Contains(fd1.Left) && fd2.Right.Contains(fd1.Right) {
+				toremove[j] = struct{}{}
+				break
+			}
+		}
+	}
+
+	// TODO: more stuff here
+
+	newFDs := []*funcdep.FuncDep{}
+	for i, fd := range ds.rel.FuncDeps {
+		if _, ok := toremove[i]; !ok {
+			newFDs = append(newFDs, fd)
+		}
+	}
+	ds.rel.FuncDeps = newFDs
+}
+
+// Relation returns the relation holding the attributes of the DataSet and
+// the functional dependencies found so far.
+func (ds *DataSet) Relation() *funcdep.Relation {
+	return ds.rel
+}
+
+// Options control how functional dependencies are discovered in a DataSet.
+type Options struct {
+	// SampleRate is the proportion of rows to analyze (0.0-1.0). All rows
+	// are used if it is outside of that range.
+	SampleRate float64
+
+	// Exclude lists attributes to leave out of the results. For exact
+	// dependencies they are still analyzed, and then projected away so that
+	// dependencies implied through them are kept. Approximate dependencies
+	// do not compose like exact ones (A->X and X->B may each hold within
+	// MaxError while A->B does not), so when MaxError is greater than 0 the
+	// excluded attributes are not analyzed at all.
+	Exclude funcdep.AttrSet
+
+	// MaxLHS limits left sides to this many attributes, if greater than 0.
+	MaxLHS int
+
+	// MaxError is the largest g3 error allowed for a dependency (0.0-1.0),
+	// i.e. the fraction of rows which may be removed for it to hold.
+	MaxError float64
+
+	// Algorithm names the discovery algorithm (see Algorithms). Defaults
+	// to "levelwise".
+	Algorithm string
+}
+
+// Result of discovering functional dependencies in a DataSet.
+type Result struct {
+	// Relation holds the attributes and the discovered dependencies.
+	Relation *funcdep.Relation
+
+	// Dependencies lists the discovered dependencies along with their
+	// errors, before they are combined in Relation. When exact dependencies
+	// are projected to leave out excluded attributes, these are the
+	// dependencies of the projection.
+	Dependencies []*Dependency
+
+	// Rows is the number of rows loaded, and SampledRows the number analyzed.
+	Rows        int
+	SampledRows int
+
+	// Columns is the number of columns analyzed.
+	Columns int
+
+	// Algorithm is the name of the discovery algorithm used.
+	Algorithm string
+
+	// Elapsed is the time taken to analyze the data.
+	Elapsed time.Duration
+
+	// data holds the analyzed rows.
+	data *DataSet
+}
+
+// Discover samples and analyzes the DataSet as described by opts. The
+// DataSet itself is not changed, so Discover may be called many times, e.g.
+// to compare algorithms.
+func (ds *DataSet) Discover(opts Options) (*Result, error) {
+	algo := opts.Algorithm
+	if algo == "" {
+		algo = "levelwise"
+	}
+	d, err := ByName(algo, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	work := ds.clone()
+	res := &Result{Rows: len(work.data), Algorithm: algo, data: work}
+	if opts.SampleRate > 0.0 && opts.SampleRate < 1.0 {
+		work.Sample(opts.SampleRate)
+	}
+	res.SampledRows = len(work.data)
+
+	project := len(opts.Exclude) > 0 && opts.MaxError <= 0
+	if len(opts.Exclude) > 0 && !project {
+		work.exclude(opts.Exclude)
+	}
+	res.Columns = len(work.header) - len(work.skiplist)
+
+	start := time.Now()
+	res.Dependencies = work.Analyze(d)
+	if project {
+		var kept funcdep.AttrSet
+		for _, a := range work.rel.Attrs {
+			if !opts.Exclude.Contains(funcdep.AttrSet{a}) {
+				kept = append(kept, a)
+			}
+		}
+		work.rel = work.rel.Project(kept)
+		res.Dependencies = nil
+		for _, fd := range work.rel.FuncDeps {
+			res.Dependencies = append(res.Dependencies, &Dependency{FuncDep: fd})
+		}
+	}
+	res.Elapsed = time.Since(start)
+	res.Relation = work.rel
+	return res, nil
+}
+
+// Simplify the functional dependencies in the Relation.
+func (r *Result) Simplify() {
+	r.data.Simplify()
+}
+
+// Violations finds the groups of analyzed rows which break the functional
+// dependency fd, like DataSet.Violations but only using the sampled rows.
+func (r *Result) Violations(fd *funcdep.FuncDep) ([]*Conflict, error) {
+	return r.data.Violations(fd)
+}
+
+// exclude skips the columns of the attributes in attrs, and removes them
+// from the Relation.
+func (ds *DataSet) exclude(attrs funcdep.AttrSet) {
+	skip := make(map[int]string, len(ds.skiplist))
+	for j, why := range ds.skiplist {
+		skip[j] = why
+	}
+	for j, h := range ds.header {
+		if _, ok := skip[j]; !ok && attrs.Contains(funcdep.AttrSet{funcdep.Attr(h)}) {
+			skip[j] = "(excluded)"
+		}
+	}
+	ds.skiplist = skip
+
+	var kept funcdep.AttrSet
+	for _, a := range ds.rel.Attrs {
+		if !attrs.Contains(funcdep.AttrSet{a}) {
+			kept = append(kept, a)
+		}
+	}
+	ds.rel.Attrs = kept
+}
+
+// clone returns a copy of the DataSet which shares the rows of data, but can
+// be sampled and analyzed separately. The copy starts out with no functional
+// dependencies.
+func (ds *DataSet) clone() *DataSet {
+	res := &DataSet{
+		skiplist: ds.skiplist,
+		header:   ds.header,
+		data:     append([][]string(nil), ds.data...),
+		rownum:   append([]int(nil), ds.rownum...),
+		rel: &funcdep.Relation{
+			Name: ds.rel.Name,
+		},
+	}
+	res.rel.Attrs = append(res.rel.Attrs, ds.rel.Attrs...)
+	return res
+}
@@ -0,0 +1,133 @@
+package discover
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/joiningdata/funcdep"
+)
+
+func TestDiscoverLeavesDataSet(t *testing.T) {
+	header := []string{"A", "B", "C"}
+	data := [][]string{
+		{"1", "x", "p"},
+		{"2", "x", "q"},
+		{"3", "y", "q"},
+		{"4", "y", "r"},
+	}
+	ds := NewDataSet("R", header, data)
+
+	opts := Options{SampleRate: 0.5, Exclude: funcdep.AttrSet{"C"}}
+	for i := 0; i < 3; i++ {
+		res, err := ds.Discover(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Rows != len(data) || res.SampledRows != len(data)/2 {
+			t.Fatalf("run %d: got %d rows sampled to %d, want %d to %d",
+				i, res.Rows, res.SampledRows, len(data), len(data)/2)
+		}
+		if res.Relation == ds.Relation() {
+			t.Fatalf("run %d: result shares the DataSet's Relation", i)
+		}
+		res.Simplify()
+	}
+
+	rel := ds.Relation()
+	if len(rel.Attrs) != len(header) || len(rel.FuncDeps) != 0 {
+		t.Fatalf("DataSet relation changed to %s", rel)
+	}
+	if len(ds.data) != len(data) {
+		t.Fatalf("DataSet has %d rows, want %d", len(ds.data), len(data))
+	}
+	for i, n := range ds.rownum {
+		if n != i+1 {
+			t.Fatalf("row %d renumbered to %d", i+1, n)
+		}
+	}
+}
+
+func TestDiscoverExcludeApproximate(t *testing.T) {
+	// A->X and X->B each have a g3 error of 0.05, but A->B has 0.10.
+	header := []string{"A", "X", "B"}
+	data := [][]string{
+		{"a1", "x1", "b1"},
+		{"a1", "x2", "b2"},
+		{"a2", "x3", "b3"},
+		{"a2", "x3", "b4"},
+	}
+	for i := 5; i <= 20; i++ {
+		v := strconv.Itoa(i)
+		data = append(data, []string{"a" + v, "x" + v, "b" + v})
+	}
+	ds := NewDataSet("R", header, data)
+
+	ab := &funcdep.FuncDep{Left: funcdep.AttrSet{"A"}, Right: funcdep.AttrSet{"B"}}
+	for _, algo := range []string{"levelwise", "pairwise"} {
+		res, err := ds.Discover(Options{MaxError: 0.06, Exclude: funcdep.AttrSet{"X"}, Algorithm: algo})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Relation.Implies(ab) {
+			t.Errorf("%s: excluding X kept %s with g3 error 0.10\n%s", algo, ab, res.Relation)
+		}
+		if !res.Relation.Implies(&funcdep.FuncDep{Left: funcdep.AttrSet{"B"}, Right: funcdep.AttrSet{"A"}}) {
+			t.Errorf("%s: excluding X lost B --> A\n%s", algo, res.Relation)
+		}
+	}
+}
+
+func TestAnalyzeTwice(t *testing.T) {
+	ds := NewDataSet("R", []string{"A", "B"}, [][]string{{"1", "x"}, {"2", "y"}})
+	ds.Analyze(Levelwise{})
+	first := len(ds.Relation().FuncDeps)
+	if first == 0 {
+		t.Fatal("found no dependencies")
+	}
+	ds.Analyze(Levelwise{})
+	if got := len(ds.Relation().FuncDeps); got != first {
+		t.Fatalf("got %d dependencies after analyzing twice, want %d", got, first)
+	}
+}
+
+func TestDiscoverExcludeErrors(t *testing.T) {
+	rng := rand.New(rand.NewSource(24))
+	for i := 0; i < 200; i++ {
+		header, rows := randomData(rng)
+		ds := NewDataSet("R", header, rows)
+		opts := Options{Exclude: funcdep.AttrSet{funcdep.Attr(header[0])}}
+		if i%2 == 1 {
+			opts.MaxError = float64(rng.Intn(3)+1) / 10
+		}
+		res, err := ds.Discover(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// every dependency in the relation is listed with its measured error
+		listed := make(map[string]float64)
+		for _, dep := range res.Dependencies {
+			for _, a := range dep.Right {
+				listed[dep.Left.String()+"->"+string(a)] = dep.Error
+			}
+		}
+		for _, fd := range res.Relation.FuncDeps {
+			var left []int
+			for _, a := range fd.Left {
+				left = append(left, int(a[0]-'A'))
+			}
+			for _, a := range fd.Right {
+				e, ok := listed[fd.Left.String()+"->"+string(a)]
+				want := g3(countViolations(rows, left, int(a[0]-'A')), len(rows))
+				if !ok || e != want || e > opts.MaxError {
+					t.Fatalf("%s --> %s listed %v with error %.3f, measured %.3f (max %.1f)\n%v",
+						fd.Left, a, ok, e, want, opts.MaxError, rows)
+				}
+				if len(fd.Left.Intersection(opts.Exclude)) > 0 || opts.Exclude.Contains(funcdep.AttrSet{a}) {
+					t.Fatalf("%s --> %s uses an excluded attribute", fd.Left, a)
+				}
+			}
+		}
+	}
+}
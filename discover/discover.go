@@ -0,0 +1,122 @@
+// Package discover finds functional dependencies which hold on tabular data.
+package discover
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joiningdata/funcdep"
+)
+
+// Discoverer finds the functional dependencies which hold on rows of data.
+// Only the columns in cols are considered, and each column is named by its
+// entry in header.
+type Discoverer interface {
+	Discover(header []string, rows [][]string, cols []int) []*Dependency
+}
+
+// Dependency is a functional dependency found in data, along with its g3
+// error: the smallest fraction of rows which must be removed for it to hold.
+// The error is 0 for dependencies which hold exactly.
+type Dependency struct {
+	*funcdep.FuncDep
+	Error float64
+}
+
+func (d *Dependency) String() string {
+	return fmt.Sprintf("%s  (error %.4f)", d.FuncDep, d.Error)
+}
+
+// algorithms lists the Discoverers available by name.
+var algorithms = map[string]func(opts Options) (Discoverer, error){
+	"pairwise": func(opts Options) (Discoverer, error) {
+		return Pairwise{MaxError: opts.MaxError}, nil
+	},
+	"levelwise": func(opts Options) (Discoverer, error) {
+		return Levelwise{MaxLHS: opts.MaxLHS, MaxError: opts.MaxError}, nil
+	},
+	"agreeset": func(opts Options) (Discoverer, error) {
+		if opts.MaxError > 0 {
+			return nil, fmt.Errorf("algorithm 'agreeset' only finds exact dependencies")
+		}
+		return AgreeSet{MaxLHS: opts.MaxLHS}, nil
+	},
+}
+
+// Algorithms returns the names of the available algorithms, sorted.
+func Algorithms() []string {
+	var names []string
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ByName returns the Discoverer for the named algorithm, configured with
+// the MaxLHS and MaxError limits of opts where supported.
+func ByName(name string, opts Options) (Discoverer, error) {
+	mk, ok := algorithms[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown algorithm '%s' (use one of %s)", name, strings.Join(Algorithms(), ", "))
+	}
+	return mk(opts)
+}
+
+// newDependency creates the dependency left->a, naming each column by its
+// header, which holds once nviol of the rows are removed.
+func newDependency(header []string, left funcdep.Bitset, a int, nviol, nrows int) *Dependency {
+	fd := &funcdep.FuncDep{}
+	left.Each(func(j int) {
+		fd.Left.Add(funcdep.Attr(header[j]))
+	})
+	fd.Right.Add(funcdep.Attr(header[a]))
+	return &Dependency{FuncDep: fd, Error: g3(nviol, nrows)}
+}
+
+// g3 is the fraction of nrows rows given by nviol.
+func g3(nviol, nrows int) float64 {
+	if nviol == 0 {
+		return 0
+	}
+	return float64(nviol) / float64(nrows)
+}
+
+// maxViolations is the largest number of the rows which may be removed for
+// a dependency to have a g3 error of at most maxError.
+func maxViolations(maxError float64, nrows int) int {
+	if maxError <= 0 {
+		return 0
+	}
+	return int(math.Floor(maxError*float64(nrows) + 1e-9))
+}
+
+func without(b funcdep.Bitset, a int) funcdep.Bitset {
+	res := b.Clone()
+	res.Clear(a)
+	return res
+}
+
+func sameCols(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// setKey is a map key for a set of columns.
+func setKey(b funcdep.Bitset) string {
+	var parts []string
+	b.Each(func(j int) {
+		parts = append(parts, strconv.Itoa(j))
+	})
+	return strings.Join(parts, ",")
+}
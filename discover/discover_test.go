@@ -0,0 +1,167 @@
+package discover
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// countViolations is the number of rows which must be removed for the
+// columns left to determine column a.
+func countViolations(rows [][]string, left []int, a int) int {
+	groups := make(map[string]map[string]int)
+	for _, row := range rows {
+		var k []string
+		for _, j := range left {
+			k = append(k, row[j])
+		}
+		key := strings.Join(k, "\t")
+		if groups[key] == nil {
+			groups[key] = make(map[string]int)
+		}
+		groups[key][row[a]]++
+	}
+	n := 0
+	for _, counts := range groups {
+		total, most := 0, 0
+		for _, c := range counts {
+			total += c
+			if c > most {
+				most = c
+			}
+		}
+		n += total - most
+	}
+	return n
+}
+
+// minimalDeps finds every minimal dependency over cols by checking each
+// subset of them, formatted as by depList.
+func minimalDeps(header []string, rows [][]string, cols []int, maxLHS int, maxError float64) string {
+	limit := maxViolations(maxError, len(rows))
+	holds := func(mask int, a int) bool {
+		var left []int
+		for p, j := range cols {
+			if mask&(1<<uint(p)) != 0 {
+				left = append(left, j)
+			}
+		}
+		return countViolations(rows, left, a) <= limit
+	}
+
+	var res []string
+	for q, a := range cols {
+		for mask := 0; mask < 1<<uint(len(cols)); mask++ {
+			if mask&(1<<uint(q)) != 0 || !holds(mask, a) {
+				continue
+			}
+			var left []string
+			minimal := true
+			for p, j := range cols {
+				if mask&(1<<uint(p)) == 0 {
+					continue
+				}
+				left = append(left, header[j])
+				if holds(mask&^(1<<uint(p)), a) {
+					minimal = false
+				}
+			}
+			if minimal && (maxLHS == 0 || len(left) <= maxLHS) {
+				res = append(res, strings.Join(left, ",")+"->"+header[a])
+			}
+		}
+	}
+	sort.Strings(res)
+	return strings.Join(res, " ")
+}
+
+// depList formats dependencies with one attribute on each right side, in
+// sorted order.
+func depList(deps []*Dependency) string {
+	var res []string
+	for _, d := range deps {
+		for _, a := range d.Right {
+			res = append(res, d.Left.String()+"->"+string(a))
+		}
+	}
+	sort.Strings(res)
+	return strings.Join(res, " ")
+}
+
+// randomData creates up to 6 columns (A, B, ...) of small random integers.
+func randomData(rng *rand.Rand) ([]string, [][]string) {
+	ncols := rng.Intn(6) + 1
+	nrows := rng.Intn(12)
+	card := rng.Intn(4) + 1
+	var header []string
+	for j := 0; j < ncols; j++ {
+		header = append(header, string(rune('A'+j)))
+	}
+	var rows [][]string
+	for i := 0; i < nrows; i++ {
+		row := make([]string, ncols)
+		for j := range row {
+			row[j] = strconv.Itoa(rng.Intn(card))
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+func TestMinimalDiscoverers(t *testing.T) {
+	rng := rand.New(rand.NewSource(21))
+	for i := 0; i < 3000; i++ {
+		header, rows := randomData(rng)
+		var cols []int
+		skip := rng.Intn(len(header) + 1)
+		for j := range header {
+			if j != skip {
+				cols = append(cols, j)
+			}
+		}
+		maxLHS := rng.Intn(4)
+		maxError := 0.0
+		if i%2 == 1 {
+			maxError = float64(rng.Intn(4)) / 10
+		}
+
+		want := minimalDeps(header, rows, cols, maxLHS, maxError)
+		ds := []Discoverer{Levelwise{MaxLHS: maxLHS, MaxError: maxError}}
+		if maxError == 0 {
+			ds = append(ds, AgreeSet{MaxLHS: maxLHS})
+		}
+		for _, d := range ds {
+			if got := depList(d.Discover(header, rows, cols)); got != want {
+				t.Fatalf("%T with MaxLHS %d, MaxError %.1f on columns %v of %v\ngot  %s\nwant %s",
+					d, maxLHS, maxError, cols, rows, got, want)
+			}
+		}
+	}
+}
+
+func TestPairwise(t *testing.T) {
+	rng := rand.New(rand.NewSource(22))
+	for i := 0; i < 1000; i++ {
+		header, rows := randomData(rng)
+		var cols []int
+		for j := range header {
+			cols = append(cols, j)
+		}
+		maxError := float64(rng.Intn(3)) / 10
+		limit := maxViolations(maxError, len(rows))
+		for _, d := range (Pairwise{MaxError: maxError}).Discover(header, rows, cols) {
+			var left []int
+			for _, a := range d.Left {
+				left = append(left, int(a[0]-'A'))
+			}
+			for _, a := range d.Right {
+				n := countViolations(rows, left, int(a[0]-'A'))
+				if n > limit || d.Error > maxError {
+					t.Fatalf("found %s with %d violating rows of %v", d, n, rows)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package discover
+
+import (
+	"github.com/joiningdata/funcdep"
+)
+
+// partition is a stripped partition of the rows of data: each class holds
+// the rows which agree on a set of columns, leaving out single rows.
+type partition struct {
+	classes [][]int
+	size    int
+}
+
+func (p *partition) add(class []int) {
+	p.classes = append(p.classes, class)
+	p.size += len(class)
+}
+
+// errorCount is the number of rows that must be removed for the columns of
+// the partition to be a key. X->A holds exactly when X and XA have the same
+// errorCount.
+func (p *partition) errorCount() int {
+	return p.size - len(p.classes)
+}
+
+// columnValues numbers the distinct values in column j, in order of appearance.
+func columnValues(data [][]string, j int) []int {
+	ids := make(map[string]int)
+	vals := make([]int, len(data))
+	for i, row := range data {
+		id, ok := ids[row[j]]
+		if !ok {
+			id = len(ids)
+			ids[row[j]] = id
+		}
+		vals[i] = id
+	}
+	return vals
+}
+
+// valuePartition builds the partition of the rows by their value ids.
+func valuePartition(vals []int) *partition {
+	var groups [][]int
+	for i, v := range vals {
+		if v == len(groups) {
+			groups = append(groups, nil)
+		}
+		groups[v] = append(groups[v], i)
+	}
+	p := &partition{}
+	for _, g := range groups {
+		if len(g) > 1 {
+			p.add(g)
+		}
+	}
+	return p
+}
+
+// violations counts the rows which must be removed so that the rows in each
+// class have the same value id, keeping the most common id in each class.
+func (p *partition) violations(vals []int) int {
+	n := 0
+	counts := make(map[int]int)
+	for _, c := range p.classes {
+		most := 0
+		for _, t := range c {
+			counts[vals[t]]++
+			if counts[vals[t]] > most {
+				most = counts[vals[t]]
+			}
+		}
+		n += len(c) - most
+		for k := range counts {
+			delete(counts, k)
+		}
+	}
+	return n
+}
+
+// product builds the partition for the union of the columns of p and q.
+// owner must have an entry for every row, all set to -1, and is reset
+// before returning.
+func (p *partition) product(q *partition, owner []int) *partition {
+	res := &partition{}
+	groups := make([][]int, len(p.classes))
+	for i, c := range p.classes {
+		for _, t := range c {
+			owner[t] = i
+		}
+	}
+	for _, c := range q.classes {
+		for _, t := range c {
+			if i := owner[t]; i >= 0 {
+				groups[i] = append(groups[i], t)
+			}
+		}
+		for _, t := range c {
+			if i := owner[t]; i >= 0 {
+				if len(groups[i]) > 1 {
+					res.add(groups[i])
+				}
+				groups[i] = nil
+			}
+		}
+	}
+	for _, c := range p.classes {
+		for _, t := range c {
+			owner[t] = -1
+		}
+	}
+	return res
+}
+
+// latticeNode is a set of columns visited by the level-wise search, along
+// with its partition and the candidate right sides C+ of the TANE algorithm.
+type latticeNode struct {
+	cols  []int
+	set   funcdep.Bitset
+	part  *partition
+	cplus funcdep.Bitset
+}
+
+// Levelwise finds the minimal functional dependencies which hold on the
+// data, using the TANE algorithm of Huhtala et al.
+//
+// Sets of columns are visited level by level, from single columns up, and
+// X->A is tested by comparing the partitions of the rows by X and by XA.
+// The partition for each set is the product of two from the level before.
+// Sets which are keys, or which cannot be the left side of any more minimal
+// dependencies, are not extended further.
+//
+// Approximate dependencies are found when MaxError is greater than 0, but
+// only exact dependencies are used to prune the search, and keys are not
+// pruned at all.
+//
+// This works best with many rows and few columns.
+type Levelwise struct {
+	// MaxLHS limits left sides to this many columns, if greater than 0.
+	MaxLHS int
+
+	// MaxError is the largest g3 error allowed for a dependency (0.0-1.0).
+	MaxError float64
+}
+
+// Discover implements the Discoverer interface.
+func (lw Levelwise) Discover(header []string, rows [][]string, cols []int) []*Dependency {
+	maxLHS := lw.MaxLHS
+	maxRows := maxViolations(lw.MaxError, len(rows))
+	ncols := len(header)
+	all := funcdep.NewBitset(ncols)
+	vals := make([][]int, ncols)
+	var level []*latticeNode
+	for _, j := range cols {
+		all.Set(j)
+		vals[j] = columnValues(rows, j)
+		n := &latticeNode{cols: []int{j}, set: funcdep.NewBitset(ncols), part: valuePartition(vals[j])}
+		n.set.Set(j)
+		level = append(level, n)
+	}
+
+	// the empty set has every row in a single class
+	empty := &latticeNode{set: funcdep.NewBitset(ncols), part: &partition{}, cplus: all.Clone()}
+	if len(rows) > 1 {
+		every := make([]int, len(rows))
+		for i := range every {
+			every[i] = i
+		}
+		empty.part.add(every)
+	}
+	prev := map[string]*latticeNode{setKey(empty.set): empty}
+
+	var res []*Dependency
+	emit := func(left funcdep.Bitset, a int, nviol int) {
+		res = append(res, newDependency(header, left, a, nviol, len(rows)))
+	}
+
+	owner := make([]int, len(rows))
+	for i := range owner {
+		owner[i] = -1
+	}
+	for size := 1; len(level) > 0; size++ {
+		cur := make(map[string]*latticeNode, len(level))
+		for _, n := range level {
+			cur[setKey(n.set)] = n
+		}
+
+		// C+(X) is the intersection of C+(X-A) for each A in X. Then X-A->A
+		// holds if the partitions agree, and no superset of X can have a
+		// minimal dependency on A, or on anything outside X. Approximate
+		// dependencies only rule out A.
+		for _, n := range level {
+			n.cplus = all.Clone()
+			for _, a := range n.cols {
+				sub, ok := prev[setKey(without(n.set, a))]
+				if !ok {
+					n.cplus = funcdep.NewBitset(ncols)
+					break
+				}
+				n.cplus = n.cplus.Intersection(sub.cplus)
+			}
+			n.set.Intersection(n.cplus).Each(func(a int) {
+				sub := prev[setKey(without(n.set, a))]
+				exact := sub.part.errorCount() == n.part.errorCount()
+				nviol := 0
+				if !exact {
+					if maxRows == 0 {
+						return
+					}
+					if nviol = sub.part.violations(vals[a]); nviol > maxRows {
+						return
+					}
+				}
+				emit(sub.set, a, nviol)
+				n.cplus.Clear(a)
+				if exact {
+					n.cplus = n.cplus.Difference(all.Difference(n.set))
+				}
+			})
+		}
+
+		// keys are not extended, so dependencies with a key on the left
+		// side are reported here instead of at the next level. They are
+		// minimal if no subset one column smaller is also a left side.
+		// An approximate dependency can still have part of a key on the
+		// right side, so keys are only pruned when finding exact ones.
+		var kept []*latticeNode
+		for _, n := range level {
+			if n.cplus.Empty() {
+				continue
+			}
+			if n.part.errorCount() != 0 || maxRows > 0 {
+				kept = append(kept, n)
+				continue
+			}
+			if maxLHS > 0 && size > maxLHS {
+				continue
+			}
+			n.cplus.Difference(n.set).Each(func(a int) {
+				for _, b := range n.cols {
+					if prev[setKey(without(n.set, b))].part.violations(vals[a]) <= maxRows {
+						return
+					}
+				}
+				emit(n.set, a, 0)
+			})
+		}
+
+		if maxLHS > 0 && size > maxLHS {
+			break
+		}
+		prev = cur
+		level = nextLevel(kept, owner)
+	}
+	return res
+}
+
+// nextLevel joins pairs of sets which differ only in their last column, when
+// every subset of the result is also in level.
+func nextLevel(level []*latticeNode, owner []int) []*latticeNode {
+	have := make(map[string]bool, len(level))
+	for _, n := range level {
+		have[setKey(n.set)] = true
+	}
+
+	var res []*latticeNode
+	for i, y := range level {
+		prefix := y.cols[:len(y.cols)-1]
+		for _, z := range level[i+1:] {
+			if !sameCols(prefix, z.cols[:len(z.cols)-1]) {
+				break
+			}
+			last := z.cols[len(z.cols)-1]
+			x := y.set.Clone()
+			x.Set(last)
+			ok := true
+			for _, a := range y.cols {
+				if !have[setKey(without(x, a))] {
+					ok = false
+					break
+				}
+			}
+			if !ok {
+				continue
+			}
+			cols := append(append([]int(nil), y.cols...), last)
+			res = append(res, &latticeNode{cols: cols, set: x, part: y.part.product(z.part, owner)})
+		}
+	}
+	return res
+}
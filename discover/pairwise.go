@@ -0,0 +1,129 @@
+package discover
+
+import (
+	"strings"
+
+	"github.com/joiningdata/funcdep"
+)
+
+// Pairwise finds functional dependencies between single columns and pairs
+// of columns, in both directions (e.g. A->B,C and B,C->A). Larger left sides
+// are not checked, and the results are not necessarily minimal.
+type Pairwise struct {
+	// MaxError is the largest g3 error allowed for a dependency (0.0-1.0).
+	MaxError float64
+}
+
+// Discover implements the Discoverer interface.
+func (pw Pairwise) Discover(header []string, rows [][]string, cols []int) []*Dependency {
+	var res []*Dependency
+
+	chkagainst := func(ii int, jj []int) {
+		jx := len(jj)
+		mx := ii + 1
+		if jx > 0 {
+			mx = jj[jx-1]
+		}
+		jj = append(jj, mx)
+		for _, j := range cols {
+			if j >= mx {
+				jj[jx] = j
+				res = append(res, CheckColumnPair(header, rows, ii, jj, pw.MaxError)...)
+			}
+		}
+	}
+
+	// for every (ordered) pair of columns,
+	// examine dependency between values
+	for _, i := range cols {
+		// 1:1 pairings
+		chkagainst(i, nil)
+
+		for _, j := range cols {
+			if j > i {
+				// 1:2 pairings
+				chkagainst(i, []int{j})
+			}
+		}
+	}
+	return res
+}
+
+// CheckColumnPair counts data co-occurance for the columns given.
+// If column i functionally determines the columns js, or the columns js
+// determine column i, then the relationship is returned. Dependencies
+// which hold after removing at most maxError of the rows are also returned.
+func CheckColumnPair(header []string, rows [][]string, i int, js []int, maxError float64) []*Dependency {
+	var res []*Dependency
+	maxRows := maxViolations(maxError, len(rows))
+
+	// value_j => count of each value_i
+	deps := make(map[string]map[string]int)
+
+	// value_i => count of each value_j
+	revdeps := make(map[string]map[string]int)
+
+	// read through the data set and track values
+	// observed for each pair
+	for _, row := range rows {
+		vi := row[i]
+		vjs := []string{}
+		for _, j := range js {
+			vjs = append(vjs, row[j])
+		}
+		vj := strings.Join(vjs, "\t")
+
+		if _, ok := deps[vj]; !ok {
+			deps[vj] = map[string]int{vi: 1}
+		} else {
+			deps[vj][vi]++
+		}
+
+		if _, ok := revdeps[vi]; !ok {
+			revdeps[vi] = map[string]int{vj: 1}
+		} else {
+			revdeps[vi][vj]++
+		}
+	}
+
+	// if all vi are unique to each vj, then j -> i
+	if nviol := violations(deps); nviol <= maxRows {
+		fd := &funcdep.FuncDep{}
+		for _, j := range js {
+			fd.Left.Add(funcdep.Attr(header[j]))
+		}
+		fd.Right.Add(funcdep.Attr(header[i]))
+		res = append(res, &Dependency{FuncDep: fd, Error: g3(nviol, len(rows))})
+	}
+
+	///////
+
+	// if all vj are unique to each vi, then i -> j
+	if nviol := violations(revdeps); nviol <= maxRows {
+		fd := &funcdep.FuncDep{}
+		fd.Left.Add(funcdep.Attr(header[i]))
+		for _, j := range js {
+			fd.Right.Add(funcdep.Attr(header[j]))
+		}
+		res = append(res, &Dependency{FuncDep: fd, Error: g3(nviol, len(rows))})
+	}
+
+	return res
+}
+
+// violations counts the rows which must be removed so that each value maps
+// to a single value, keeping the most common one.
+func violations(counts map[string]map[string]int) int {
+	n := 0
+	for _, vals := range counts {
+		total, most := 0, 0
+		for _, c := range vals {
+			total += c
+			if c > most {
+				most = c
+			}
+		}
+		n += total - most
+	}
+	return n
+}
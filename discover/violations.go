@@ -0,0 +1,157 @@
+package discover
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/joiningdata/funcdep"
+)
+
+// maxListedRows limits the row numbers shown for each value of a Conflict.
+const maxListedRows = 10
+
+// Conflict is a group of rows which agree on the left side of a functional
+// dependency, but not on its right side.
+type Conflict struct {
+	FuncDep *funcdep.FuncDep
+
+	// Left holds the values of the left side attributes, in order.
+	Left []string
+
+	// Right lists each distinct set of right side values found with Left,
+	// most common first.
+	Right []*ConflictValue
+}
+
+// ConflictValue is one set of right side values in a Conflict, along with
+// the numbers of the rows where it appears.
+type ConflictValue struct {
+	Values []string
+	Rows   []int
+}
+
+// Violations is the number of rows which must be removed to resolve the
+// conflict, keeping the most common right side values.
+func (c *Conflict) Violations() int {
+	n := 0
+	for _, v := range c.Right[1:] {
+		n += len(v.Rows)
+	}
+	return n
+}
+
+func (c *Conflict) String() string {
+	lines := []string{assignments(c.FuncDep.Left, c.Left) + ":"}
+	for _, v := range c.Right {
+		var rows []string
+		for i, r := range v.Rows {
+			if i == maxListedRows {
+				rows = append(rows, "...")
+				break
+			}
+			rows = append(rows, fmt.Sprint(r))
+		}
+		noun := "rows"
+		if len(v.Rows) == 1 {
+			noun = "row"
+		}
+		lines = append(lines, fmt.Sprintf("    %s  %d %s: %s",
+			assignments(c.FuncDep.Right, v.Values), len(v.Rows), noun, strings.Join(rows, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// assignments lists the attributes with their values, e.g. A=1, B=2
+func assignments(attrs funcdep.AttrSet, vals []string) string {
+	parts := make([]string, len(attrs))
+	for i, a := range attrs {
+		parts[i] = fmt.Sprintf("%s=%q", a, vals[i])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Violations finds the groups of rows which break the functional dependency
+// fd, i.e. which agree on the left side but not on the right side. Conflicts
+// with the most violating rows are listed first. Rows are numbered as they
+// were loaded, counting from 1 after the header, even if the DataSet has
+// been sampled.
+func (ds *DataSet) Violations(fd *funcdep.FuncDep) ([]*Conflict, error) {
+	left, err := ds.columns(fd.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := ds.columns(fd.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	values := func(row []string, cols []int) []string {
+		res := make([]string, len(cols))
+		for i, j := range cols {
+			res[i] = row[j]
+		}
+		return res
+	}
+
+	groups := make(map[string]*Conflict)
+	byRight := make(map[string]map[string]*ConflictValue)
+	var order []string
+	for i, row := range ds.data {
+		lv := values(row, left)
+		lkey := strings.Join(lv, "\t")
+		c, ok := groups[lkey]
+		if !ok {
+			c = &Conflict{FuncDep: fd, Left: lv}
+			groups[lkey] = c
+			byRight[lkey] = make(map[string]*ConflictValue)
+			order = append(order, lkey)
+		}
+		rv := values(row, right)
+		rkey := strings.Join(rv, "\t")
+		v, ok := byRight[lkey][rkey]
+		if !ok {
+			v = &ConflictValue{Values: rv}
+			byRight[lkey][rkey] = v
+			c.Right = append(c.Right, v)
+		}
+		v.Rows = append(v.Rows, ds.rownum[i])
+	}
+
+	var res []*Conflict
+	for _, lkey := range order {
+		c := groups[lkey]
+		if len(c.Right) < 2 {
+			continue
+		}
+		sort.SliceStable(c.Right, func(i, j int) bool {
+			return len(c.Right[i].Rows) > len(c.Right[j].Rows)
+		})
+		for _, v := range c.Right {
+			sort.Ints(v.Rows)
+		}
+		res = append(res, c)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Violations() > res[j].Violations()
+	})
+	return res, nil
+}
+
+// columns finds the column of each attribute.
+func (ds *DataSet) columns(attrs funcdep.AttrSet) ([]int, error) {
+	res := make([]int, len(attrs))
+	for i, a := range attrs {
+		res[i] = -1
+		for j, h := range ds.header {
+			if _, skip := ds.skiplist[j]; !skip && h == string(a) {
+				res[i] = j
+				break
+			}
+		}
+		if res[i] == -1 {
+			return nil, fmt.Errorf("unknown attribute '%s'", a)
+		}
+	}
+	return res, nil
+}
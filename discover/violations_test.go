@@ -0,0 +1,116 @@
+package discover
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/joiningdata/funcdep"
+)
+
+func violationsData() *DataSet {
+	header := []string{"A", "B", "C"}
+	data := [][]string{
+		{"1", "x", "p"}, // row 1
+		{"1", "y", "p"}, // row 2
+		{"2", "x", "q"}, // row 3
+		{"2", "y", "q"}, // row 4
+		{"2", "y", "r"}, // row 5
+		{"2", "z", "r"}, // row 6
+		{"3", "x", "s"}, // row 7
+		{"1", "x", "t"}, // row 8
+	}
+	return NewDataSet("R", header, data)
+}
+
+func TestViolations(t *testing.T) {
+	ds := violationsData()
+	fd := &funcdep.FuncDep{Left: funcdep.AttrSet{"A"}, Right: funcdep.AttrSet{"B"}}
+	conflicts, err := ds.Violations(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A=3 agrees with itself, so only A=2 (2 violating rows) and A=1 (1
+	// violating row) conflict, with the larger group first.
+	if len(conflicts) != 2 {
+		t.Fatalf("got %d conflicts, want 2", len(conflicts))
+	}
+	type value struct {
+		vals string
+		rows []int
+	}
+	want := []struct {
+		left  string
+		viol  int
+		right []value
+	}{
+		{"2", 2, []value{{"y", []int{4, 5}}, {"x", []int{3}}, {"z", []int{6}}}},
+		{"1", 1, []value{{"x", []int{1, 8}}, {"y", []int{2}}}},
+	}
+	for i, w := range want {
+		c := conflicts[i]
+		if c.Left[0] != w.left || c.Violations() != w.viol {
+			t.Fatalf("conflict %d is A=%s with %d violations, want A=%s with %d",
+				i, c.Left[0], c.Violations(), w.left, w.viol)
+		}
+		if len(c.Right) != len(w.right) {
+			t.Fatalf("conflict A=%s has %d values, want %d", w.left, len(c.Right), len(w.right))
+		}
+		for j, v := range w.right {
+			if c.Right[j].Values[0] != v.vals || !reflect.DeepEqual(c.Right[j].Rows, v.rows) {
+				t.Fatalf("conflict A=%s value %d is B=%s in rows %v, want B=%s in rows %v",
+					w.left, j, c.Right[j].Values[0], c.Right[j].Rows, v.vals, v.rows)
+			}
+		}
+	}
+
+	if _, err := ds.Violations(&funcdep.FuncDep{Left: funcdep.AttrSet{"A"}, Right: funcdep.AttrSet{"Q"}}); err == nil {
+		t.Error("unknown attribute Q was accepted")
+	}
+}
+
+func TestViolationsHolds(t *testing.T) {
+	ds := violationsData()
+	fd := &funcdep.FuncDep{Left: funcdep.AttrSet{"A", "B"}, Right: funcdep.AttrSet{"A"}}
+	conflicts, err := ds.Violations(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("got %d conflicts for a dependency which holds", len(conflicts))
+	}
+}
+
+func TestViolationsAfterSample(t *testing.T) {
+	ds := violationsData()
+	orig := make(map[int][]string)
+	for i, row := range ds.data {
+		orig[i+1] = row
+	}
+	ds.Sample(0.5)
+
+	fd := &funcdep.FuncDep{Left: funcdep.AttrSet{"C"}, Right: funcdep.AttrSet{"B"}}
+	conflicts, err := ds.Violations(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rows keep the numbers they were loaded with
+	for _, c := range conflicts {
+		for _, v := range c.Right {
+			for _, n := range v.Rows {
+				row, ok := orig[n]
+				if !ok || row[2] != c.Left[0] || row[1] != v.Values[0] {
+					t.Fatalf("row %d is %v, not C=%s, B=%s", n, row, c.Left[0], v.Values[0])
+				}
+			}
+		}
+	}
+	if len(ds.rownum) != 4 {
+		t.Fatalf("sampled %d rows, want 4", len(ds.rownum))
+	}
+	for i, n := range ds.rownum {
+		if !reflect.DeepEqual(ds.data[i], orig[n]) {
+			t.Fatalf("sampled row %v is numbered %d, which was %v", ds.data[i], n, orig[n])
+		}
+	}
+}
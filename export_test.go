@@ -0,0 +1,4 @@
+package funcdep
+
+// RandomRelation is exported for the tests in package funcdep_test.
+var RandomRelation = randomRelation
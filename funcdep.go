@@ -19,31 +19,78 @@ func (fd *FuncDep) String() string {
 	return fd.Left.String() + " --> " + fd.Right.String()
 }
 
+// MVD represents a multivalued dependency of the form:
+//    Left ->> Right
+type MVD struct {
+	Left  AttrSet
+	Right AttrSet
+}
+
+// String representation of the multivalued dependency (joined by an ASCII double arrow).
+func (m *MVD) String() string {
+	return m.Left.String() + " ->> " + m.Right.String()
+}
+
 // accepts multiple forms of left->right arrows:
-//   > --> ---> >> -->>
-//   ~~> ~> ==> ===>>
+//   > --> ---> ~~> ~> ==>
 //   → ⇒ ⇾  (Unicode arrows)
-var cutArrows = regexp.MustCompile("[-=~]*[>→⇒⇾]+")
+// and of double-headed arrows for multivalued dependencies:
+//   >> ->> -->> ===>>
+//   ↠  (Unicode arrow)
+var cutArrows = regexp.MustCompile("[-=~]*[>→⇒⇾↠]+")
+
+// isMVDArrow returns true if the arrow is double-headed.
+func isMVDArrow(arrow string) bool {
+	return strings.HasSuffix(arrow, ">>") || strings.ContainsRune(arrow, '↠')
+}
 
 // FromString converts a text/string description of a functional dependency into
 // a parsed FuncDep structure. It accepts multiple forms of arrows in the
 // representation (as long as they point to the right).
 func FromString(fdesc string) (*FuncDep, error) {
-	parts := cutArrows.Split(fdesc, -1)
+	left, right, multi, err := parseDependency(fdesc)
+	if err != nil {
+		return nil, err
+	}
+	if multi {
+		return nil, fmt.Errorf("multivalued dependency arrow in functional dependency")
+	}
+	return &FuncDep{Left: left, Right: right}, nil
+}
+
+// MVDFromString converts a text/string description of a multivalued
+// dependency into a parsed MVD structure. It accepts multiple forms of
+// double-headed arrows in the representation (as long as they point to the
+// right).
+func MVDFromString(mdesc string) (*MVD, error) {
+	left, right, multi, err := parseDependency(mdesc)
+	if err != nil {
+		return nil, err
+	}
+	if !multi {
+		return nil, fmt.Errorf("functional dependency arrow in multivalued dependency")
+	}
+	return &MVD{Left: left, Right: right}, nil
+}
+
+// parseDependency splits a dependency description at its arrow, returning
+// the attributes on each side and whether the arrow was double-headed.
+func parseDependency(desc string) (left, right AttrSet, multi bool, err error) {
+	parts := cutArrows.Split(desc, -1)
 	if len(parts) == 1 {
-		return nil, fmt.Errorf("no arrow found in functional dependency")
+		return nil, nil, false, fmt.Errorf("no arrow found in dependency")
 	}
 	if len(parts) != 2 {
-		return nil, fmt.Errorf("too many arrows in functional dependency")
+		return nil, nil, false, fmt.Errorf("too many arrows in dependency")
 	}
-	fd := &FuncDep{}
+	multi = isMVDArrow(cutArrows.FindString(desc))
 	for _, s := range strings.Split(parts[0], AttrSep) {
 		a := Attr(strings.TrimSpace(s))
-		fd.Left = append(fd.Left, a)
+		left = append(left, a)
 	}
 	for _, s := range strings.Split(parts[1], AttrSep) {
 		a := Attr(strings.TrimSpace(s))
-		fd.Right = append(fd.Right, a)
+		right = append(right, a)
 	}
-	return fd, nil
+	return left, right, multi, nil
 }
@@ -0,0 +1,49 @@
+package funcdep
+
+import "testing"
+
+func TestFromString(t *testing.T) {
+	for _, s := range []string{"A,B -> C", "A,B --> C", "A,B > C", "A,B ==> C", "A,B → C"} {
+		fd, err := FromString(s)
+		if err != nil {
+			t.Errorf("FromString(%q): %v", s, err)
+			continue
+		}
+		if got := fd.String(); got != "A,B --> C" {
+			t.Errorf("FromString(%q) = %s", s, got)
+		}
+	}
+	for _, s := range []string{"A ->> B", "A -->> B", "A >> B", "A ↠ B", "A B", "A -> B -> C"} {
+		if fd, err := FromString(s); err == nil {
+			t.Errorf("FromString(%q) = %s, want an error", s, fd)
+		}
+	}
+}
+
+func TestMVDFromString(t *testing.T) {
+	for _, s := range []string{"A ->> B,C", "A -->> B,C", "A >> B,C", "A ↠ B,C"} {
+		m, err := MVDFromString(s)
+		if err != nil {
+			t.Errorf("MVDFromString(%q): %v", s, err)
+			continue
+		}
+		if got := m.String(); got != "A ->> B,C" {
+			t.Errorf("MVDFromString(%q) = %s", s, got)
+		}
+	}
+	for _, s := range []string{"A -> B", "A --> B", "A → B"} {
+		if m, err := MVDFromString(s); err == nil {
+			t.Errorf("MVDFromString(%q) = %s, want an error", s, m)
+		}
+	}
+}
+
+func TestRelationFromStringMVD(t *testing.T) {
+	rel, err := RelationFromString("R(A,B,C)\nA -> B\nA -->> C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rel.FuncDeps) != 1 || len(rel.MVDs) != 1 {
+		t.Fatalf("got %d functional and %d multivalued dependencies", len(rel.FuncDeps), len(rel.MVDs))
+	}
+}
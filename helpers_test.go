@@ -0,0 +1,43 @@
+package funcdep
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// randomRelation creates a relation with nattrs attributes (A1, A2, ...) and
+// nfds random functional dependencies, with left sides of up to maxLeft
+// attributes and right sides of up to 2 attributes.
+func randomRelation(rng *rand.Rand, nattrs, nfds, maxLeft int) *Relation {
+	r := &Relation{Name: "R"}
+	for i := 1; i <= nattrs; i++ {
+		r.Attrs.Add(Attr(fmt.Sprintf("A%d", i)))
+	}
+	for i := 0; i < nfds; i++ {
+		fd := &FuncDep{}
+		for n := rng.Intn(maxLeft) + 1; n > 0; n-- {
+			fd.Left.Add(r.Attrs[rng.Intn(nattrs)])
+		}
+		for n := rng.Intn(2) + 1; n > 0; n-- {
+			fd.Right.Add(r.Attrs[rng.Intn(nattrs)])
+		}
+		r.FuncDeps = append(r.FuncDeps, fd)
+	}
+	return r
+}
+
+// randomMVDs adds n random multivalued dependencies to rel, with left sides
+// of up to 2 attributes and right sides of up to 2 attributes.
+func randomMVDs(rng *rand.Rand, rel *Relation, n int) {
+	nattrs := len(rel.Attrs)
+	for i := 0; i < n; i++ {
+		m := &MVD{}
+		for k := rng.Intn(2) + 1; k > 0; k-- {
+			m.Left.Add(rel.Attrs[rng.Intn(nattrs)])
+		}
+		for k := rng.Intn(2) + 1; k > 0; k-- {
+			m.Right.Add(rel.Attrs[rng.Intn(nattrs)])
+		}
+		rel.MVDs = append(rel.MVDs, m)
+	}
+}
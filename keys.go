@@ -0,0 +1,323 @@
+package funcdep
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// CandidateKeysLO enumerates every candidate key of the relation using the
+// algorithm of Lucchesi and Osborn. Each new key is derived from a known key
+// K and a functional dependency X->Y by minimizing X ∪ (K - Y), so the running
+// time is polynomial in the number of attributes, dependencies and keys,
+// rather than exponential in the number of attributes.
+//
+// Keys are returned from smallest to largest.
+func (r *Relation) CandidateKeysLO() []AttrSet {
+	keys, _, _ := r.CandidateKeysLOContext(context.Background(), KeySearch{})
+	return keys
+}
+
+// KeySearch bounds a candidate key search. Zero values mean no limit.
+type KeySearch struct {
+	// MaxKeySize is the largest number of attributes in a reported key.
+	MaxKeySize int
+
+	// MaxResults stops the search once this many keys have been found.
+	MaxResults int
+
+	// Workers is the number of goroutines used by the brute-force search.
+	// Values less than 1 use a single worker.
+	Workers int
+}
+
+// CandidateKeysLOContext is like CandidateKeysLO, but stops when the context
+// is cancelled or the limits in lim are reached. The keys found so far are
+// returned along with whether they are every candidate key of the relation.
+// If the search was interrupted by the context, its error is also returned.
+func (r *Relation) CandidateKeysLOContext(ctx context.Context, lim KeySearch) ([]AttrSet, bool, error) {
+	var res []AttrSet
+	complete, err := r.WalkCandidateKeysLO(ctx, lim, func(k AttrSet) bool {
+		res = append(res, k)
+		return true
+	})
+	sortKeys(res)
+	return res, complete, err
+}
+
+// CandidateKeysBFContext is like CandidateKeysBF, but stops when the context
+// is cancelled or the limits in lim are reached. The keys found so far are
+// returned along with whether they are every candidate key of the relation.
+// If the search was interrupted by the context, its error is also returned.
+//
+// Keys are searched level by level from smallest to largest, so MaxKeySize
+// bounds the depth of the search and not just the reported keys.
+func (r *Relation) CandidateKeysBFContext(ctx context.Context, lim KeySearch) ([]AttrSet, bool, error) {
+	var res []AttrSet
+	complete, err := r.WalkCandidateKeysBF(ctx, lim, func(k AttrSet) bool {
+		res = append(res, k)
+		return true
+	})
+	return res, complete, err
+}
+
+// WalkCandidateKeysLO calls fn with each candidate key as soon as it is found
+// by the Lucchesi-Osborn algorithm. The search stops early if fn returns
+// false, the context is cancelled, or the limits in lim are reached. Returns
+// whether every candidate key of the relation was passed to fn, and the
+// context's error if the search was interrupted by it.
+//
+// Keys are found in no particular order, but the order is deterministic.
+func (r *Relation) WalkCandidateKeysLO(ctx context.Context, lim KeySearch, fn func(AttrSet) bool) (bool, error) {
+	c := r.newCloser()
+	return c.walkKeysLO(ctx, lim, func(k Bitset) bool {
+		return fn(c.ix.AttrSet(k))
+	})
+}
+
+// WalkCandidateKeysBF calls fn with each candidate key as soon as it is found
+// by a level-wise brute-force search, smallest keys first. The search stops
+// early if fn returns false, the context is cancelled, or the limits in lim
+// are reached. Returns whether every candidate key of the relation was passed
+// to fn, and the context's error if the search was interrupted by it.
+func (r *Relation) WalkCandidateKeysBF(ctx context.Context, lim KeySearch, fn func(AttrSet) bool) (bool, error) {
+	c := r.newCloser()
+	return r.walkKeysBF(ctx, c, lim, func(k Bitset) bool {
+		return fn(c.ix.AttrSet(k))
+	})
+}
+
+// walkKeysLO calls yield for each candidate key found by the Lucchesi-Osborn
+// algorithm, until yield returns false or the search is cancelled or limited.
+func (c *closer) walkKeysLO(ctx context.Context, lim KeySearch, yield func(Bitset) bool) (bool, error) {
+	complete := true
+	found := 0
+	emit := func(k Bitset) bool {
+		if lim.MaxKeySize > 0 && k.Len() > lim.MaxKeySize {
+			// larger keys are still needed to derive the rest
+			complete = false
+			return true
+		}
+		found++
+		if !yield(k) {
+			return false
+		}
+		return lim.MaxResults <= 0 || found < lim.MaxResults
+	}
+
+	keys := []Bitset{c.minimizeKey(c.all)}
+	if !emit(keys[0]) {
+		return false, nil
+	}
+	for i := 0; i < len(keys); i++ {
+		for _, fd := range c.fds {
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+			s := fd.left.Union(keys[i].Difference(fd.right))
+			if containsAnyKey(s, keys) {
+				continue
+			}
+			k := c.minimizeKey(s)
+			keys = append(keys, k)
+			if !emit(k) {
+				return false, nil
+			}
+		}
+	}
+	return complete, nil
+}
+
+// walkKeysBF calls yield for each candidate key found by a level-wise
+// brute-force search, until yield returns false or the search is cancelled
+// or limited. Each level only extends attribute sets which do not contain a
+// key from a previous level, so every superkey found is a candidate key.
+//
+// Within a level, the sets are partitioned by their first attribute and
+// searched by lim.Workers goroutines. Keys are yielded in partition order,
+// so the results do not depend on the number of workers.
+func (r *Relation) walkKeysBF(ctx context.Context, c *closer, lim KeySearch, yield func(Bitset) bool) (bool, error) {
+	// attributes that never appear on a right side are part of every key,
+	// and attributes that only appear on right sides are part of none.
+	left, _, both, neither := r.AttrClasses()
+	core := c.ix.Bits(left.Union(neither))
+	if lim.MaxKeySize > 0 && core.Len() > lim.MaxKeySize {
+		return false, nil
+	}
+	if c.isSuperkey(core) {
+		yield(core)
+		return true, nil
+	}
+	pool := make([]int, len(both))
+	for i, a := range both {
+		pool[i], _ = c.ix.Pos(a)
+	}
+	depth := len(pool)
+	if lim.MaxKeySize > 0 && lim.MaxKeySize-core.Len() < depth {
+		depth = lim.MaxKeySize - core.Len()
+	}
+	workers := lim.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	// the closer is shared read-only by all workers
+	c.prepare()
+
+	wctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	var keys []Bitset
+	found := 0
+	for size := 1; size <= depth; size++ {
+		parts := make([]bfPartition, len(pool)-size+1)
+		for i := range parts {
+			parts[i].done = make(chan struct{})
+		}
+		next := int64(-1)
+		prev := keys
+		for w := 0; w < workers && w < len(parts); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					i := int(atomic.AddInt64(&next, 1))
+					if i >= len(parts) {
+						return
+					}
+					parts[i].search(wctx, c, prev, core, pool[i], pool[i+1:], size)
+					close(parts[i].done)
+				}
+			}()
+		}
+
+		// open is set if some set at this level is not a superkey,
+		// i.e. there may be more keys at the next level.
+		open := false
+		for i := range parts {
+			<-parts[i].done
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+			open = open || parts[i].open
+			for _, k := range parts[i].keys {
+				found++
+				if !yield(k) || (lim.MaxResults > 0 && found >= lim.MaxResults) {
+					return false, nil
+				}
+				keys = append(keys, k)
+			}
+		}
+		if !open {
+			return true, nil
+		}
+	}
+	return depth == len(pool), nil
+}
+
+// bfPartition holds the results of searching one partition of a level of
+// the brute-force key search.
+type bfPartition struct {
+	keys []Bitset
+	open bool
+	done chan struct{}
+}
+
+// search finds the candidate keys made of core, first, and size-1 positions
+// from rest, skipping any that contain one of the smaller keys.
+func (p *bfPartition) search(ctx context.Context, c *closer, keys []Bitset, core Bitset, first int, rest []int, size int) {
+	steps := 0
+	check := func(x Bitset, last bool) bool {
+		if steps++; steps%1024 == 0 && ctx.Err() != nil {
+			return false
+		}
+		if containsAnyKey(x, keys) {
+			return false
+		}
+		if !last {
+			return true
+		}
+		if !c.isSuperkey(x) {
+			p.open = true
+			return false
+		}
+		p.keys = append(p.keys, x.Clone())
+		return false
+	}
+
+	x := core.Clone()
+	x.Set(first)
+	if check(x, size == 1) && size > 1 {
+		recurBF(rest, x, size-1, check)
+	}
+}
+
+// recurBF extends x with every combination of size attribute positions
+// from pool, calling visit on each partial combination along the way (last
+// is true once size positions have been added). A combination is only
+// extended further if visit returns true.
+func recurBF(pool []int, x Bitset, size int, visit func(x Bitset, last bool) bool) {
+	for i, a1 := range pool {
+		if len(pool)-i < size {
+			return
+		}
+		x.Set(a1)
+		if visit(x, size == 1) && size > 1 {
+			recurBF(pool[i+1:], x, size-1, visit)
+		}
+		x.Clear(a1)
+	}
+}
+
+// minimizeKey removes attributes from the superkey x until it is a candidate key.
+func (c *closer) minimizeKey(x Bitset) Bitset {
+	key := x.Clone()
+	x.Each(func(a int) {
+		key.Clear(a)
+		if !c.isSuperkey(key) {
+			key.Set(a)
+		}
+	})
+	return key
+}
+
+// containsAnyKey returns true if x contains at least one of the keys.
+func containsAnyKey(x Bitset, keys []Bitset) bool {
+	for _, k := range keys {
+		if x.Contains(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortKeys orders keys from smallest to largest, then alphabetically.
+func sortKeys(keys []AttrSet) {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.String()
+	}
+	sort.Sort(keySorter{keys, names})
+}
+
+type keySorter struct {
+	keys  []AttrSet
+	names []string
+}
+
+func (s keySorter) Len() int { return len(s.keys) }
+func (s keySorter) Less(i, j int) bool {
+	if len(s.keys[i]) != len(s.keys[j]) {
+		return len(s.keys[i]) < len(s.keys[j])
+	}
+	return s.names[i] < s.names[j]
+}
+func (s keySorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.names[i], s.names[j] = s.names[j], s.names[i]
+}
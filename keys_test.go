@@ -0,0 +1,115 @@
+package funcdep
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// allKeys finds every candidate key by checking each subset of the
+// attributes.
+func allKeys(rel *Relation) []AttrSet {
+	var keys []AttrSet
+	for mask := 0; mask < 1<<uint(len(rel.Attrs)); mask++ {
+		var x AttrSet
+		for i, a := range rel.Attrs {
+			if mask&(1<<uint(i)) != 0 {
+				x = append(x, a)
+			}
+		}
+		if rel.IsKey(x) {
+			keys = append(keys, x)
+		}
+	}
+	return keys
+}
+
+// keyList formats keys in a canonical order for comparison.
+func keyList(keys []AttrSet) string {
+	var res []string
+	for _, k := range keys {
+		k = append(AttrSet(nil), k...)
+		sortAttrs(k)
+		res = append(res, k.String())
+	}
+	sort.Strings(res)
+	return strings.Join(res, " | ")
+}
+
+func TestCandidateKeys(t *testing.T) {
+	rng := rand.New(rand.NewSource(10))
+	ctx := context.Background()
+	for i := 0; i < 300; i++ {
+		rel := randomRelation(rng, rng.Intn(8)+1, rng.Intn(10), 3)
+		want := allKeys(rel)
+
+		if got := keyList(rel.CandidateKeysLO()); got != keyList(want) {
+			t.Fatalf("LO keys of\n%s\ngot  %s\nwant %s", rel, got, keyList(want))
+		}
+		for _, workers := range []int{1, 4} {
+			keys, complete, err := rel.CandidateKeysBFContext(ctx, KeySearch{Workers: workers})
+			if got := keyList(keys); got != keyList(want) || !complete || err != nil {
+				t.Fatalf("BF keys with %d workers of\n%s\ngot  %s (%v, %v)\nwant %s",
+					workers, rel, got, complete, err, keyList(want))
+			}
+		}
+
+		size := rng.Intn(4) + 1
+		var small []AttrSet
+		for _, k := range want {
+			if len(k) <= size {
+				small = append(small, k)
+			}
+		}
+		lim := KeySearch{MaxKeySize: size, Workers: 2}
+		lo, loComplete, _ := rel.CandidateKeysLOContext(ctx, lim)
+		bf, bfComplete, _ := rel.CandidateKeysBFContext(ctx, lim)
+		for _, keys := range [][]AttrSet{lo, bf} {
+			for _, k := range keys {
+				if len(k) > size || !rel.IsKey(k) {
+					t.Fatalf("keys of\n%s\nlimited to %d attributes include %s", rel, size, k)
+				}
+			}
+		}
+		if got := keyList(bf); got != keyList(small) {
+			t.Fatalf("BF keys of\n%s\nlimited to %d attributes got %s, want %s", rel, size, got, keyList(small))
+		}
+		// a search may only report completion once it has every key
+		if (loComplete || bfComplete) && len(small) != len(want) {
+			t.Fatalf("keys of\n%s\nlimited to %d attributes reported complete LO=%v BF=%v",
+				rel, size, loComplete, bfComplete)
+		}
+	}
+}
+
+func TestCandidateKeysCancelled(t *testing.T) {
+	rel := randomRelation(rand.New(rand.NewSource(1)), 12, 20, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, complete, err := rel.CandidateKeysLOContext(ctx, KeySearch{}); complete || err != context.Canceled {
+		t.Errorf("LO search after cancel: complete=%v, err=%v", complete, err)
+	}
+	if _, complete, err := rel.CandidateKeysBFContext(ctx, KeySearch{Workers: 2}); complete || err != context.Canceled {
+		t.Errorf("BF search after cancel: complete=%v, err=%v", complete, err)
+	}
+}
+
+func TestCandidateKeysMaxResults(t *testing.T) {
+	rel, err := RelationFromString(`R(A,B,C,D)
+A --> B
+B --> A
+C --> D
+D --> C`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := keyList(rel.CandidateKeysLO()); got != "A,C | A,D | B,C | B,D" {
+		t.Fatalf("got keys %s", got)
+	}
+	keys, complete, err := rel.CandidateKeysLOContext(context.Background(), KeySearch{MaxResults: 2})
+	if len(keys) != 2 || complete || err != nil {
+		t.Fatalf("got %d keys (%v, %v), want 2 incomplete", len(keys), complete, err)
+	}
+}
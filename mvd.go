@@ -0,0 +1,222 @@
+package funcdep
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DependencyBasis computes the dependency basis of the attribute set x: a
+// partition of the other attributes of the Relation into blocks, such that
+// x ->> Y holds exactly when Y - x is a union of blocks.
+//
+// Every attribute functionally determined by x is a block of its own, and
+// each functional dependency V->W also contributes the multivalued
+// dependencies V ->> A for every attribute A in W.
+func (r *Relation) DependencyBasis(x AttrSet) []AttrSet {
+	c := r.newCloser()
+	blocks := c.dependencyBasis(r.basisDeps(c), c.ix.Bits(x))
+	res := make([]AttrSet, len(blocks))
+	for i, b := range blocks {
+		res[i] = c.ix.AttrSet(b)
+	}
+	return res
+}
+
+// ImpliesMVD returns true if the multivalued dependency m is implied by the
+// dependencies on this Relation.
+func (r *Relation) ImpliesMVD(m *MVD) bool {
+	c := r.newCloser()
+	x := c.ix.Bits(m.Left)
+	y := c.ix.Bits(m.Right).Difference(x)
+	for _, b := range c.dependencyBasis(r.basisDeps(c), x) {
+		if b.Intersects(y) && !y.Contains(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// FourNFViolations lists the non-trivial dependencies on the Relation with a
+// left side that is not a superkey. The Relation is in fourth normal form if
+// there are none. Functional dependencies X->Y are reported as X ->> Y.
+func (r *Relation) FourNFViolations() []*MVD {
+	c := r.withCoalescedFDs().newCloser()
+	var res []*MVD
+	check := func(left, right AttrSet) {
+		x := c.ix.Bits(left)
+		y := c.ix.Bits(right)
+		if x.Contains(y) || x.Union(y).Contains(c.all) || c.isSuperkey(x) {
+			return
+		}
+		res = append(res, &MVD{Left: left, Right: right})
+	}
+	for _, fd := range r.FuncDeps {
+		check(fd.Left, fd.Right)
+	}
+	for _, m := range r.MVDs {
+		check(m.Left, m.Right)
+	}
+	return res
+}
+
+// DecomposeFourNF splits the Relation into a set of relations in fourth
+// normal form. Each relation carries a minimal cover of the functional
+// dependencies projected onto its attributes, along with the multivalued
+// dependencies that still apply, and is named after the original relation
+// (e.g. R_1, R_2, ...).
+//
+// Relations are split on a violating X ->> Y into XY and X(R-Y), so the
+// decomposition always has a lossless join.
+func (r *Relation) DecomposeFourNF() []*Relation {
+	eff := r.withCoalescedFDs()
+	c := eff.newCloser()
+	deps := eff.basisDeps(c)
+
+	var done []Bitset
+	work := []Bitset{c.all}
+	for len(work) > 0 {
+		s := work[0]
+		work = work[1:]
+
+		x, y, ok := eff.fourNFSplit(c, deps, s)
+		if !ok {
+			done = append(done, s)
+			continue
+		}
+		work = append([]Bitset{x.Union(y), s.Difference(y)}, work...)
+	}
+
+	res := make([]*Relation, len(done))
+	for i, s := range done {
+		part := eff.Project(c.ix.AttrSet(s))
+		part.Name = fmt.Sprintf("%s_%d", r.Name, i+1)
+		res[i] = part
+	}
+	return res
+}
+
+// fourNFSplit finds a multivalued dependency X ->> Y which holds on the
+// attributes in s and violates fourth normal form there.
+func (r *Relation) fourNFSplit(c *closer, deps []bitFD, s Bitset) (x, y Bitset, ok bool) {
+	var lefts []Bitset
+	for _, d := range deps {
+		lefts = append(lefts, d.left)
+	}
+	for _, fd := range r.projectFDs(c.ix.AttrSet(s)) {
+		lefts = append(lefts, c.ix.Bits(fd.Left))
+	}
+
+	for _, x := range lefts {
+		if !s.Contains(x) || c.closure(x).Contains(s) {
+			continue
+		}
+		rest := s.Difference(x)
+		for _, b := range c.dependencyBasis(deps, x) {
+			y := b.Intersection(s)
+			if !y.Empty() && !y.Equal(rest) {
+				return x, y, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// withCoalescedFDs returns a copy of the Relation including the functional
+// dependencies implied by combining its multivalued and functional
+// dependencies (coalescence): X->A holds when {A} is a block of the
+// dependency basis of X, and A is on the right side of a functional
+// dependency V->W which does not contain A on the left.
+func (r *Relation) withCoalescedFDs() *Relation {
+	c := r.newCloser()
+	deps := r.basisDeps(c)
+
+	determined := NewBitset(c.ix.Len())
+	for _, fd := range c.fds {
+		determined.UnionWith(fd.right.Difference(fd.left))
+	}
+
+	res := &Relation{Name: r.Name, Attrs: r.Attrs, MVDs: r.MVDs}
+	res.FuncDeps = append(res.FuncDeps, r.FuncDeps...)
+	for _, m := range r.MVDs {
+		x := c.ix.Bits(m.Left)
+		clo := c.closure(x)
+		extra := NewBitset(c.ix.Len())
+		for _, b := range c.dependencyBasis(deps, x) {
+			if b.Len() == 1 && determined.Contains(b) && !clo.Contains(b) {
+				extra.UnionWith(b)
+			}
+		}
+		if !extra.Empty() {
+			res.FuncDeps = append(res.FuncDeps, &FuncDep{Left: m.Left, Right: c.ix.AttrSet(extra)})
+		}
+	}
+	return res
+}
+
+// basisDeps returns the multivalued dependencies of the Relation, along
+// with X ->> A for each attribute A on the right of a functional dependency.
+func (r *Relation) basisDeps(c *closer) []bitFD {
+	var deps []bitFD
+	for _, m := range r.MVDs {
+		deps = append(deps, bitFD{left: c.ix.Bits(m.Left), right: c.ix.Bits(m.Right)})
+	}
+	for _, fd := range c.fds {
+		fd.right.Difference(fd.left).Each(func(a int) {
+			right := NewBitset(c.ix.Len())
+			right.Set(a)
+			deps = append(deps, bitFD{left: fd.left, right: right})
+		})
+	}
+	return deps
+}
+
+// dependencyBasis computes the dependency basis of x by repeatedly splitting
+// blocks: a block B is split by V ->> W when V is disjoint from B and W
+// contains only part of B.
+func (c *closer) dependencyBasis(deps []bitFD, x Bitset) []Bitset {
+	clo := c.closure(x)
+	var blocks []Bitset
+	clo.Difference(x).Each(func(a int) {
+		b := NewBitset(c.ix.Len())
+		b.Set(a)
+		blocks = append(blocks, b)
+	})
+	if rest := c.all.Difference(clo); !rest.Empty() {
+		blocks = append(blocks, rest)
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for i := 0; i < len(blocks); i++ {
+			for _, d := range deps {
+				b := blocks[i]
+				if d.left.Intersects(b) {
+					continue
+				}
+				in := b.Intersection(d.right)
+				if in.Empty() || in.Equal(b) {
+					continue
+				}
+				blocks[i] = in
+				blocks = append(blocks, b.Difference(d.right))
+				changed = true
+			}
+		}
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		return firstPos(blocks[i]) < firstPos(blocks[j])
+	})
+	return blocks
+}
+
+// firstPos returns the smallest position in the set, or -1 if it is empty.
+func firstPos(b Bitset) int {
+	pos := -1
+	b.Each(func(i int) {
+		if pos == -1 {
+			pos = i
+		}
+	})
+	return pos
+}
@@ -0,0 +1,144 @@
+package funcdep
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// chaseMVD decides if x ->> y holds using the chase: it does exactly when
+// splitting the relation into xy and x(R-y) is lossless.
+func chaseMVD(rel *Relation, x, y AttrSet) bool {
+	xy := x.Union(y)
+	rest := x.Union(rel.Attrs.Difference(y))
+	ok, _, err := LosslessJoin(rel, []AttrSet{xy, rest})
+	return ok && err == nil
+}
+
+func TestImpliesMVD(t *testing.T) {
+	rng := rand.New(rand.NewSource(14))
+	for i := 0; i < 300; i++ {
+		rel := randomRelation(rng, rng.Intn(4)+2, rng.Intn(4), 2)
+		randomMVDs(rng, rel, rng.Intn(3))
+		for n := 0; n < 5; n++ {
+			m := &MVD{}
+			for k := rng.Intn(2) + 1; k > 0; k-- {
+				m.Left.Add(rel.Attrs[rng.Intn(len(rel.Attrs))])
+			}
+			for k := rng.Intn(3) + 1; k > 0; k-- {
+				m.Right.Add(rel.Attrs[rng.Intn(len(rel.Attrs))])
+			}
+			if got, want := rel.ImpliesMVD(m), chaseMVD(rel, m.Left, m.Right); got != want {
+				t.Fatalf("ImpliesMVD(%s) = %v, chase says %v\n%s", m, got, want, rel)
+			}
+		}
+	}
+}
+
+func TestDependencyBasis(t *testing.T) {
+	rng := rand.New(rand.NewSource(41))
+	for i := 0; i < 300; i++ {
+		rel := randomRelation(rng, rng.Intn(4)+2, rng.Intn(4), 2)
+		randomMVDs(rng, rel, rng.Intn(3))
+		x := AttrSet{rel.Attrs[rng.Intn(len(rel.Attrs))]}
+
+		// the blocks partition R-X, and X ->> B holds for each block B
+		var seen AttrSet
+		for _, b := range rel.DependencyBasis(x) {
+			if len(b) == 0 || len(b.Intersection(seen, x)) > 0 {
+				t.Fatalf("basis of %s has overlapping block %s\n%s", x, b, rel)
+			}
+			seen.AddAll(b)
+			if !chaseMVD(rel, x, b) {
+				t.Fatalf("basis of %s has block %s which X does not multidetermine\n%s", x, b, rel)
+			}
+		}
+		if !sameSet(seen.Union(x), rel.Attrs) {
+			t.Fatalf("basis of %s covers only %s\n%s", x, seen, rel)
+		}
+	}
+}
+
+func TestDependencyBasisExample(t *testing.T) {
+	rel, err := RelationFromString(`R(A,B,C,D,E)
+A ->> B,C
+C --> D`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, b := range rel.DependencyBasis(AttrSet{"A"}) {
+		got = append(got, b.String())
+	}
+	// C --> D splits D from A's other block, but not B from C
+	want := []string{"B,C", "D", "E"}
+	if len(got) != len(want) {
+		t.Fatalf("got basis %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got basis %v, want %v", got, want)
+		}
+	}
+}
+
+func TestCoalescedFDs(t *testing.T) {
+	// A ->> B and D --> B with D outside of B give A --> B
+	rel, err := RelationFromString(`R(A,B,C,D)
+A ->> B
+D --> B`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab := &FuncDep{Left: AttrSet{"A"}, Right: AttrSet{"B"}}
+	if rel.Implies(ab) {
+		t.Fatal("A --> B implied without coalescence")
+	}
+	if !rel.withCoalescedFDs().Implies(ab) {
+		t.Fatalf("coalescence did not add %s", ab)
+	}
+	ac := &FuncDep{Left: AttrSet{"A"}, Right: AttrSet{"C"}}
+	if rel.withCoalescedFDs().Implies(ac) {
+		t.Fatalf("coalescence added %s", ac)
+	}
+}
+
+func TestFourNFViolations(t *testing.T) {
+	rel, err := RelationFromString(`R(Course,Teacher,Book)
+Course ->> Teacher`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs := rel.FourNFViolations()
+	if len(vs) != 1 || vs[0].String() != "Course ->> Teacher" {
+		t.Fatalf("got violations %v", vs)
+	}
+
+	rel.FuncDeps = append(rel.FuncDeps, &FuncDep{Left: AttrSet{"Course"}, Right: AttrSet{"Teacher", "Book"}})
+	if vs := rel.FourNFViolations(); len(vs) != 0 {
+		t.Fatalf("got violations %v with Course as a key", vs)
+	}
+}
+
+func TestDecomposeFourNF(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for i := 0; i < 200; i++ {
+		rel := randomRelation(rng, rng.Intn(4)+2, rng.Intn(4), 2)
+		randomMVDs(rng, rel, rng.Intn(3))
+
+		var parts []AttrSet
+		var all AttrSet
+		for _, part := range rel.DecomposeFourNF() {
+			if vs := part.FourNFViolations(); len(vs) > 0 {
+				t.Fatalf("part %s of\n%s\nviolates 4NF with %v", part.Attrs, rel, vs)
+			}
+			parts = append(parts, part.Attrs)
+			all.AddAll(part.Attrs)
+		}
+		if !sameSet(all, rel.Attrs) {
+			t.Fatalf("4NF decomposition %v of\n%s\ndoes not cover the attributes", parts, rel)
+		}
+		if ok, tab, _ := LosslessJoin(rel, parts); !ok {
+			t.Fatalf("4NF decomposition %v of\n%s\nis lossy\n%s", parts, rel, tab)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package funcdep
+
+import "fmt"
+
+// NormalForm identifies a level of database normalization.
+type NormalForm int
+
+// Normal forms in increasing order of strictness. Every Relation is assumed
+// to be in first normal form (i.e. attribute values are atomic).
+const (
+	FirstNF NormalForm = iota + 1
+	SecondNF
+	ThirdNF
+	BCNF
+)
+
+func (nf NormalForm) String() string {
+	switch nf {
+	case FirstNF:
+		return "1NF"
+	case SecondNF:
+		return "2NF"
+	case ThirdNF:
+		return "3NF"
+	case BCNF:
+		return "BCNF"
+	}
+	return fmt.Sprintf("NormalForm(%d)", int(nf))
+}
+
+// Violation describes a functional dependency that prevents a Relation from
+// satisfying a normal form.
+type Violation struct {
+	// FuncDep is the offending functional dependency.
+	FuncDep *FuncDep
+
+	// Violates is the lowest normal form that the dependency violates.
+	Violates NormalForm
+
+	// Reason is a human-readable explanation of the violation.
+	Reason string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s violates %s: %s", v.FuncDep, v.Violates, v.Reason)
+}
+
+// NormalForm determines the highest normal form satisfied by the Relation,
+// along with every dependency in the minimal cover that violates a stricter
+// normal form.
+func (r *Relation) NormalForm() (NormalForm, []Violation) {
+	return r.NormalFormWithKeys(r.CandidateKeysLO())
+}
+
+// NormalFormWithKeys is like NormalForm, but uses keys as the candidate keys
+// of the Relation instead of searching for them. keys must list every
+// candidate key (e.g. from a complete CandidateKeysLOContext search) for the
+// result to be correct.
+func (r *Relation) NormalFormWithKeys(keys []AttrSet) (NormalForm, []Violation) {
+	prime := primeAttrs(keys)
+
+	c := r.newCloser()
+	var res []Violation
+	partial := false
+	for _, fd := range r.MinimalCover() {
+		if c.isSuperkey(c.ix.Bits(fd.Left)) {
+			continue
+		}
+		if prime.Contains(fd.Right) {
+			res = append(res, Violation{
+				FuncDep:  fd,
+				Violates: BCNF,
+				Reason:   fmt.Sprintf("determinant %s is not a superkey", fd.Left),
+			})
+			continue
+		}
+
+		var key AttrSet
+		for _, k := range keys {
+			if len(k) > len(fd.Left) && k.Contains(fd.Left) {
+				key = k
+				break
+			}
+		}
+		if key != nil {
+			partial = true
+			res = append(res, Violation{
+				FuncDep:  fd,
+				Violates: SecondNF,
+				Reason:   fmt.Sprintf("partial dependency on candidate key %s", key),
+			})
+			continue
+		}
+
+		reason := fmt.Sprintf("non-prime attribute %s depends on non-superkey %s", fd.Right, fd.Left)
+		if via := fd.Left.Difference(prime); len(via) > 0 {
+			reason = fmt.Sprintf("transitive dependency through non-prime attributes %s", via)
+		}
+		res = append(res, Violation{
+			FuncDep:  fd,
+			Violates: ThirdNF,
+			Reason:   reason,
+		})
+	}
+
+	// a partial dependency may be implied without appearing in the cover,
+	// so check the maximal proper subsets of every key directly.
+	if !partial {
+		for _, k := range keys {
+			for _, a := range k {
+				sub := k.Difference(AttrSet{a})
+				dep := r.AttrClosure(sub).Difference(sub, prime)
+				if len(dep) == 0 {
+					continue
+				}
+				sortAttrs(dep)
+				res = append(res, Violation{
+					FuncDep:  &FuncDep{Left: sub, Right: dep},
+					Violates: SecondNF,
+					Reason:   fmt.Sprintf("partial dependency on candidate key %s", k),
+				})
+			}
+		}
+	}
+
+	nf := BCNF
+	for _, v := range res {
+		if v.Violates <= nf {
+			nf = v.Violates - 1
+		}
+	}
+	return nf, res
+}
@@ -0,0 +1,103 @@
+package funcdep
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// bruteNormalForm classifies the Relation from the definitions, by checking
+// the closure of every subset of its attributes.
+func bruteNormalForm(rel *Relation) NormalForm {
+	keys := allKeys(rel)
+	prime := primeAttrs(keys)
+	nf := BCNF
+	for mask := 0; mask < 1<<uint(len(rel.Attrs)); mask++ {
+		var x AttrSet
+		for i, a := range rel.Attrs {
+			if mask&(1<<uint(i)) != 0 {
+				x = append(x, a)
+			}
+		}
+		if rel.IsSuperkey(x) {
+			continue
+		}
+		dep := rel.AttrClosure(x).Difference(x)
+		if len(dep) == 0 {
+			continue
+		}
+		if nf > ThirdNF {
+			nf = ThirdNF
+		}
+		nonPrime := dep.Difference(prime)
+		if len(nonPrime) == 0 {
+			continue
+		}
+		if nf > SecondNF {
+			nf = SecondNF
+		}
+		for _, k := range keys {
+			if len(k) > len(x) && k.Contains(x) {
+				return FirstNF
+			}
+		}
+	}
+	return nf
+}
+
+func TestNormalForm(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	for i := 0; i < 2000; i++ {
+		rel := randomRelation(rng, rng.Intn(6)+1, rng.Intn(8), 3)
+		nf, vs := rel.NormalForm()
+		if want := bruteNormalForm(rel); nf != want {
+			t.Fatalf("normal form of\n%s\ngot %s, want %s: %v", rel, nf, want, vs)
+		}
+		for _, v := range vs {
+			if v.Violates <= nf {
+				t.Fatalf("%s reported for\n%s\nwhich is in %s", v, rel, nf)
+			}
+		}
+	}
+}
+
+func TestNormalFormExamples(t *testing.T) {
+	for _, tc := range []struct {
+		rel  string
+		nf   NormalForm
+		want []string
+	}{
+		{"R(A,B,C)\nA --> B\nA --> C", BCNF, nil},
+		{"R(A,B,C)\nA,B --> C\nC --> B", ThirdNF, []string{
+			"C --> B violates BCNF: determinant C is not a superkey",
+		}},
+		{"R(A,B,C)\nA --> B\nB --> C", SecondNF, []string{
+			"B --> C violates 3NF: transitive dependency through non-prime attributes B",
+		}},
+		{"R(A,B,C)\nA,B --> C\nA --> C", FirstNF, []string{
+			"A --> C violates 2NF: partial dependency on candidate key A,B",
+		}},
+		// A,C --> E is only implied, through A,C --> B and A,B --> E
+		{"R(A,B,C,D,E)\nA,C --> B\nB,D --> A\nA,B --> E", FirstNF, []string{
+			"A,B --> E violates 3NF: non-prime attribute E depends on non-superkey A,B",
+			"A,C --> B violates BCNF: determinant A,C is not a superkey",
+			"B,D --> A violates BCNF: determinant B,D is not a superkey",
+			"A,C --> E violates 2NF: partial dependency on candidate key A,C,D",
+			"B,D --> E violates 2NF: partial dependency on candidate key B,C,D",
+		}},
+	} {
+		rel, err := RelationFromString(tc.rel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nf, vs := rel.NormalForm()
+		var got []string
+		for _, v := range vs {
+			got = append(got, v.String())
+		}
+		if nf != tc.nf || strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+			t.Errorf("normal form of\n%s\ngot %s with\n%s\nwant %s with\n%s",
+				rel, nf, strings.Join(got, "\n"), tc.nf, strings.Join(tc.want, "\n"))
+		}
+	}
+}
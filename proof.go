@@ -0,0 +1,211 @@
+package funcdep
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Axiom names an inference rule used in a Proof.
+type Axiom string
+
+// Inference rules used in proofs. Reflexivity, transitivity, union and
+// projectivity are Armstrong's axioms and the rules derived from them.
+//
+// Projectivity derives X->Y from X->YZ. It is often called decomposition,
+// but FuncDep.Decompose splits a dependency into one for every attribute on
+// its right side, while a proof only needs the part it uses next.
+const (
+	AxiomGiven        Axiom = "given"
+	AxiomReflexivity  Axiom = "reflexivity"
+	AxiomTransitivity Axiom = "transitivity"
+	AxiomUnion        Axiom = "union"
+	AxiomProjectivity Axiom = "projectivity"
+)
+
+// ProofStep is a single line of a Proof.
+type ProofStep struct {
+	// FuncDep is the functional dependency derived in this step.
+	FuncDep *FuncDep
+
+	// Axiom is the rule used to derive the dependency.
+	Axiom Axiom
+
+	// Premises are the indexes of the earlier steps used by the rule.
+	Premises []int
+}
+
+// Proof is a step-by-step derivation of a functional dependency, where the
+// last step is the dependency being proven.
+type Proof []ProofStep
+
+func (p Proof) String() string {
+	width := 0
+	fds := make([]string, len(p))
+	for i, step := range p {
+		fds[i] = step.FuncDep.String()
+		if len(fds[i]) > width {
+			width = len(fds[i])
+		}
+	}
+	numWidth := len(fmt.Sprint(len(p)))
+	lines := make([]string, len(p))
+	for i, step := range p {
+		line := fmt.Sprintf("%*d. %-*s  %s", numWidth, i+1, width, fds[i], step.Axiom)
+		if len(step.Premises) > 0 {
+			refs := make([]string, len(step.Premises))
+			for j, k := range step.Premises {
+				refs[j] = fmt.Sprint(k + 1)
+			}
+			line += " (" + strings.Join(refs, ", ") + ")"
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Explain builds a proof that the functional dependencies of rel imply fd,
+// using Armstrong's axioms. Only the given dependencies actually needed are
+// used, and each step is a single application of the rule it names to the
+// earlier steps it relies on. Transitivity and union steps are derived with
+// FuncDep.TransitiveWith and FuncDep.Union, while reflexivity and projectivity
+// steps (which have no FuncDep method) are built directly.
+// Returns false if fd is not implied.
+func Explain(rel *Relation, fd *FuncDep) (Proof, bool) {
+	if !rel.Implies(fd) {
+		return nil, false
+	}
+
+	var proof Proof
+	add := func(res *FuncDep, ax Axiom, premises ...int) int {
+		proof = append(proof, ProofStep{FuncDep: res, Axiom: ax, Premises: premises})
+		return len(proof) - 1
+	}
+	// apply adds the result of an axiom method, which always applies to
+	// the premises chosen below.
+	apply := func(res *FuncDep, ok bool, ax Axiom, premises ...int) int {
+		if !ok {
+			panic("funcdep: " + string(ax) + " does not apply to proof step")
+		}
+		return add(res, ax, premises...)
+	}
+
+	newFD := func(left, right AttrSet) *FuncDep {
+		res := &FuncDep{}
+		res.Left.AddAll(left)
+		res.Right.AddAll(right)
+		return res
+	}
+
+	if fd.Left.Contains(fd.Right) {
+		add(newFD(fd.Left, fd.Right), AxiomReflexivity)
+		return proof, true
+	}
+
+	// derived finds an earlier step X->V, or returns -1.
+	derived := func(v AttrSet) int {
+		for i, step := range proof {
+			if sameSet(step.FuncDep.Left, fd.Left) && sameSet(step.FuncDep.Right, v) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	// done finishes the proof from step i, which derives X->Z with Z
+	// containing the target's right side.
+	done := func(i int) (Proof, bool) {
+		if !fd.Right.Contains(proof[i].FuncDep.Right) {
+			add(newFD(fd.Left, fd.Right), AxiomProjectivity, i)
+		}
+		return proof, true
+	}
+
+	// for each needed V->W (with V in the closure so far): X->V by
+	// reflexivity or projectivity (unless already derived), then X->W by
+	// transitivity (unless V is X). The X->W steps are only combined by
+	// union into X->Z when a later step needs part of Z, and the proof
+	// stops once the target is derived.
+	var pending []int
+	last := -1
+	combine := func() int {
+		if last < 0 {
+			last = add(newFD(fd.Left, fd.Left), AxiomReflexivity)
+		}
+		for _, t := range pending {
+			res, ok := proof[last].FuncDep.Union(proof[t].FuncDep)
+			last = apply(res, ok, AxiomUnion, last, t)
+		}
+		pending = nil
+		return last
+	}
+	for _, given := range neededFuncDeps(rel, fd) {
+		g := add(given, AxiomGiven)
+
+		t := g
+		if !sameSet(given.Left, fd.Left) {
+			v := derived(given.Left)
+			if v < 0 {
+				if fd.Left.Contains(given.Left) {
+					v = add(newFD(fd.Left, given.Left), AxiomReflexivity)
+				} else {
+					v = add(newFD(fd.Left, given.Left), AxiomProjectivity, combine())
+				}
+			}
+			res, ok := proof[v].FuncDep.TransitiveWith(given)
+			t = apply(res, ok, AxiomTransitivity, v, g)
+		}
+		if given.Right.Contains(fd.Right) {
+			return done(t)
+		}
+		pending = append(pending, t)
+	}
+	return done(combine())
+}
+
+// neededFuncDeps returns a minimal list of the functional dependencies of
+// rel which imply fd, in the order they are applied when computing the
+// closure of its left side.
+func neededFuncDeps(rel *Relation, fd *FuncDep) []*FuncDep {
+	used := closureOrder(rel.FuncDeps, fd.Left)
+
+	// drop any dependency the rest can do without, latest first
+	for i := len(used) - 1; i >= 0; i-- {
+		var rest []*FuncDep
+		rest = append(rest, used[:i]...)
+		rest = append(rest, used[i+1:]...)
+		if (&Relation{FuncDeps: rest}).Implies(fd) {
+			used = rest
+		}
+	}
+	return closureOrder(used, fd.Left)
+}
+
+// closureOrder lists the functional dependencies which add attributes to
+// the closure of x, in the order they are applied.
+func closureOrder(fds []*FuncDep, x AttrSet) []*FuncDep {
+	var clo AttrSet
+	clo.AddAll(x)
+	applied := make([]bool, len(fds))
+	var order []*FuncDep
+	for changed := true; changed; {
+		changed = false
+		for i, fd := range fds {
+			if applied[i] || !clo.Contains(fd.Left) {
+				continue
+			}
+			applied[i] = true
+			if clo.Contains(fd.Right) {
+				continue
+			}
+			clo.AddAll(fd.Right)
+			order = append(order, fd)
+			changed = true
+		}
+	}
+	return order
+}
+
+// sameSet returns true if a and b contain the same attributes.
+func sameSet(a, b AttrSet) bool {
+	return a.Contains(b) && b.Contains(a)
+}
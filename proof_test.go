@@ -0,0 +1,105 @@
+package funcdep
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// checkStep returns an error if the step is not a single application of its
+// rule to its premises.
+func checkStep(rel *Relation, p Proof, i int) error {
+	step := p[i]
+	fd := step.FuncDep
+	var pre []*FuncDep
+	for _, k := range step.Premises {
+		if k >= i {
+			return fmt.Errorf("premise %d is not an earlier step", k+1)
+		}
+		pre = append(pre, p[k].FuncDep)
+	}
+	ok := false
+	switch step.Axiom {
+	case AxiomGiven:
+		for _, g := range rel.FuncDeps {
+			ok = ok || (sameSet(g.Left, fd.Left) && sameSet(g.Right, fd.Right))
+		}
+	case AxiomReflexivity:
+		ok = len(pre) == 0 && fd.Left.Contains(fd.Right)
+	case AxiomProjectivity:
+		ok = len(pre) == 1 && sameSet(pre[0].Left, fd.Left) && pre[0].Right.Contains(fd.Right)
+	case AxiomTransitivity:
+		ok = len(pre) == 2 && sameSet(pre[0].Left, fd.Left) &&
+			sameSet(pre[0].Right, pre[1].Left) && sameSet(pre[1].Right, fd.Right)
+	case AxiomUnion:
+		ok = len(pre) == 2 && sameSet(pre[0].Left, fd.Left) && sameSet(pre[1].Left, fd.Left) &&
+			sameSet(pre[0].Right.Union(pre[1].Right), fd.Right)
+	}
+	if !ok {
+		return fmt.Errorf("step %d (%s) is not valid %s", i+1, fd, step.Axiom)
+	}
+	return nil
+}
+
+func TestExplainSteps(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for it := 0; it < 500; it++ {
+		rel := randomRelation(rng, 6, 1+rng.Intn(6), 2)
+		for n := 0; n < 10; n++ {
+			fd := &FuncDep{}
+			for k := rng.Intn(3) + 1; k > 0; k-- {
+				fd.Left.Add(rel.Attrs[rng.Intn(len(rel.Attrs))])
+			}
+			for k := rng.Intn(2) + 1; k > 0; k-- {
+				fd.Right.Add(rel.Attrs[rng.Intn(len(rel.Attrs))])
+			}
+
+			proof, ok := Explain(rel, fd)
+			if ok != rel.Implies(fd) {
+				t.Fatalf("Explain(%s) = %v, Implies = %v\n%s", fd, ok, !ok, rel)
+			}
+			if !ok {
+				continue
+			}
+			for i := range proof {
+				if err := checkStep(rel, proof, i); err != nil {
+					t.Fatalf("proof of %s: %v\n%s\n%s", fd, err, rel, proof)
+				}
+				// the proof should stop as soon as the target is derived
+				got := proof[i].FuncDep
+				if i < len(proof)-1 && sameSet(got.Left, fd.Left) && sameSet(got.Right, fd.Right) {
+					t.Fatalf("proof of %s continues after step %d\n%s", fd, i+1, proof)
+				}
+			}
+			last := proof[len(proof)-1].FuncDep
+			if !sameSet(last.Left, fd.Left) || !sameSet(last.Right, fd.Right) {
+				t.Fatalf("proof of %s ends with %s\n%s", fd, last, proof)
+			}
+		}
+	}
+}
+
+func TestExplainExample(t *testing.T) {
+	rel, err := RelationFromString("R(A,B,C,D,E)\nA --> B\nB,C --> D\nD --> E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, _ := FromString("A,C --> E")
+	proof, ok := Explain(rel, fd)
+	if !ok {
+		t.Fatal("A,C --> E should be implied")
+	}
+	want := ` 1. A --> B        given
+ 2. A,C --> A      reflexivity
+ 3. A,C --> B      transitivity (2, 1)
+ 4. B,C --> D      given
+ 5. A,C --> A,C    reflexivity
+ 6. A,C --> A,B,C  union (5, 3)
+ 7. A,C --> B,C    projectivity (6)
+ 8. A,C --> D      transitivity (7, 4)
+ 9. D --> E        given
+10. A,C --> E      transitivity (8, 9)`
+	if got := proof.String(); got != want {
+		t.Errorf("got proof\n%s\nwant\n%s", got, want)
+	}
+}
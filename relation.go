@@ -1,8 +1,8 @@
 package funcdep
 
 import (
+	"context"
 	"fmt"
-	"sort"
 	"strings"
 )
 
@@ -16,6 +16,9 @@ type Relation struct {
 
 	// FuncDeps contains all of the functional dependencies over the Relation.
 	FuncDeps []*FuncDep
+
+	// MVDs contains all of the multivalued dependencies over the Relation.
+	MVDs []*MVD
 }
 
 func (r *Relation) String() string {
@@ -23,10 +26,14 @@ func (r *Relation) String() string {
 	for _, fd := range r.FuncDeps {
 		line += fd.String() + "\n"
 	}
+	for _, m := range r.MVDs {
+		line += m.String() + "\n"
+	}
 	return strings.TrimSpace(line)
 }
 
-// RelationFromString parses a relation and optional set of functional dependencies from a string.
+// RelationFromString parses a relation and optional set of functional (and
+// multivalued) dependencies from a string.
 func RelationFromString(desc string) (*Relation, error) {
 	lines := strings.Split(desc, "\n")
 	head := strings.TrimSpace(lines[0])
@@ -49,11 +56,15 @@ func RelationFromString(desc string) (*Relation, error) {
 		if line == "" {
 			continue
 		}
-		fd, err := FromString(line)
+		left, right, multi, err := parseDependency(line)
 		if err != nil {
 			return nil, err
 		}
-		r.FuncDeps = append(r.FuncDeps, fd)
+		if multi {
+			r.MVDs = append(r.MVDs, &MVD{Left: left, Right: right})
+		} else {
+			r.FuncDeps = append(r.FuncDeps, &FuncDep{Left: left, Right: right})
+		}
 	}
 
 	var problems AttrSet
@@ -65,6 +76,13 @@ func RelationFromString(desc string) (*Relation, error) {
 			problems.AddAll(remAttr)
 		}
 	}
+	for _, m := range r.MVDs {
+		a := m.Left.Union(m.Right)
+		remAttr := a.Difference(r.Attrs)
+		if len(remAttr) != 0 {
+			problems.AddAll(remAttr)
+		}
+	}
 
 	if len(problems) > 0 {
 		return nil, fmt.Errorf("relation has %d attributes (%v). FD has %d unknown attributes (%v)",
@@ -76,31 +94,94 @@ func RelationFromString(desc string) (*Relation, error) {
 
 // Closures computes the closure over every Functional Dependency.
 func (r *Relation) Closures() []*FuncDep {
+	c := r.newCloser()
 	res := make([]*FuncDep, len(r.FuncDeps))
 	for i, fd := range r.FuncDeps {
-		res[i] = r.Closure(fd)
+		res[i] = c.closeFD(fd)
 	}
 	return res
 }
 
 // Closure computes the closure of the given FD over the functional dependencies on this Relation.
 func (r *Relation) Closure(fd *FuncDep) *FuncDep {
-	clofd := &FuncDep{}
-	clofd.Left.AddAll(fd.Left)
-	clofd.Right.AddAll(fd.Left, fd.Right)
-
-	lastN := 0
-	n := len(clofd.Right)
-	for n != lastN {
-		for _, other := range r.FuncDeps {
-			if clofd.Right.Contains(other.Left) {
-				clofd.Right.AddAll(other.Right)
-			}
+	return r.newCloser().closeFD(fd)
+}
+
+// AttrClosure computes the closure of the attribute set x (often written X⁺)
+// over the functional dependencies on this Relation, i.e. every attribute
+// that is functionally determined by x.
+func (r *Relation) AttrClosure(x AttrSet) AttrSet {
+	c := r.newCloser()
+	return c.ix.AttrSet(c.closure(c.ix.Bits(x)))
+}
+
+// IsSuperkey returns true if the attribute set x functionally determines
+// every attribute in the Relation.
+func (r *Relation) IsSuperkey(x AttrSet) bool {
+	c := r.newCloser()
+	return c.isSuperkey(c.ix.Bits(x))
+}
+
+// IsKey returns true if the attribute set x is a candidate key of the
+// Relation, i.e. it is a superkey and no proper subset of it is a superkey.
+func (r *Relation) IsKey(x AttrSet) bool {
+	c := r.newCloser()
+	return c.isKey(c.ix.Bits(x))
+}
+
+// AttrClasses partitions the attributes of the Relation by where they
+// appear in its (non-trivial) functional dependencies: only on left sides,
+// only on right sides, on both sides, or in neither.
+//
+// Attributes in left and neither are part of every candidate key, and
+// attributes in right are never part of a candidate key.
+func (r *Relation) AttrClasses() (left, right, both, neither AttrSet) {
+	var lhs, rhs AttrSet
+	for _, fd := range r.FuncDeps {
+		lhs.AddAll(fd.Left)
+		rhs.AddAll(fd.Right.Difference(fd.Left))
+	}
+	for _, a := range r.Attrs {
+		inLeft := lhs.Contains(AttrSet{a})
+		inRight := rhs.Contains(AttrSet{a})
+		switch {
+		case inLeft && inRight:
+			both = append(both, a)
+		case inLeft:
+			left = append(left, a)
+		case inRight:
+			right = append(right, a)
+		default:
+			neither = append(neither, a)
 		}
-		lastN = n
-		n = len(clofd.Right)
 	}
-	return clofd
+	return left, right, both, neither
+}
+
+// PrimeAttrs returns the attributes of the Relation which are part of at
+// least one candidate key.
+func (r *Relation) PrimeAttrs() AttrSet {
+	return primeAttrs(r.CandidateKeysLO())
+}
+
+// NonPrimeAttrs returns the attributes of the Relation which are not part of
+// any candidate key.
+func (r *Relation) NonPrimeAttrs() AttrSet {
+	var res AttrSet
+	prime := r.PrimeAttrs()
+	for _, a := range r.Attrs {
+		if !prime.Contains(AttrSet{a}) {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+func primeAttrs(keys []AttrSet) AttrSet {
+	var prime AttrSet
+	prime.AddAll(keys...)
+	sortAttrs(prime)
+	return prime
 }
 
 // CandidateKeys returns a list of possible keys for the relation.
@@ -142,94 +223,10 @@ func (r *Relation) CandidateKeysAlt() []AttrSet {
 }
 
 // CandidateKeysBF enumerates all possible keys for the relation using a
-// brute-force approach. To reduce number of results, candidate keys that
-// contain smaller candidate keys are removed from the result set.
+// brute-force approach. Attribute sets are checked from smallest to largest,
+// and sets containing a smaller candidate key are skipped, so only minimal
+// keys are returned.
 func (r *Relation) CandidateKeysBF() []AttrSet {
-	return r.filterContainingKeys(r.enumerateCandidateKeys())
-}
-
-func (r *Relation) filterContainingKeys(candidates []AttrSet) []AttrSet {
-	// removes candidate keys that fully contain smaller
-	// candidate keys recursively.
-
-	if len(candidates) <= 1 {
-		return candidates
-	}
-
-	// sort candidates so the smallest is at the end
-	sort.Slice(candidates, func(i, j int) bool {
-		return len(candidates[i]) > len(candidates[j])
-	})
-
-	var result []AttrSet
-	for len(candidates) > 0 {
-		// add the last candidate key to the result
-		n := len(candidates) - 1
-		x := candidates[n]
-		result = append(result, x)
-
-		// to remove containing keys, copy keys that don't
-		// contain X forward, then truncate the list
-		j := 0
-		for i := 0; i < n; i++ {
-			if !candidates[i].Contains(x) {
-				if j != i {
-					candidates[j] = candidates[i]
-				}
-				j++
-			}
-		}
-		candidates = candidates[:j]
-	}
-	return result
-}
-
-func (r *Relation) enumerateCandidateKeys() []AttrSet {
-	var result []AttrSet
-
-	clos := r.Closures()
-	hits := make(map[string]struct{})
-
-	check := func(a AttrSet) {
-		if _, ok := hits[a.String()]; ok {
-			return
-		}
-		var right AttrSet
-		right.AddAll(a)
-		last := 0
-		n := len(right)
-		for n != last {
-			for _, c := range clos {
-				if right.Contains(c.Left) {
-					right.AddAll(c.Right)
-				}
-			}
-			last = n
-			n = len(right)
-		}
-		if len(right) == len(r.Attrs) {
-			var x AttrSet
-			x.AddAll(a)
-			result = append(result, x)
-		}
-		hits[a.String()] = struct{}{}
-	}
-
-	r.recurBF(nil, len(r.Attrs), check)
-	return result
-}
-
-func (r *Relation) recurBF(x AttrSet, nremain int, check func(AttrSet)) {
-	var z AttrSet
-	z.AddAll(x)
-	for _, a1 := range r.Attrs {
-		if z.Add(a1) {
-			check(z)
-			if nremain > 1 {
-				r.recurBF(z, nremain-1, check)
-			}
-
-			z.Remove(a1)
-		}
-	}
+	keys, _, _ := r.CandidateKeysBFContext(context.Background(), KeySearch{})
+	return keys
 }